@@ -0,0 +1,301 @@
+package enlight
+
+import (
+	"encoding"
+	"encoding/xml"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+
+	json "github.com/json-iterator/go"
+	"github.com/valyala/fasthttp"
+)
+
+type (
+	// Binder is the interface that wraps the Bind method.
+	Binder interface {
+		Bind(i interface{}, c Context) error
+	}
+
+	// DefaultBinder is the default implementation of the Binder interface.
+	DefaultBinder struct{}
+
+	// BindUnmarshaler is the interface used to wrap the UnmarshalParam method.
+	// Types that don't implement this, but do implement encoding.TextUnmarshaler
+	// will use that interface instead.
+	BindUnmarshaler interface {
+		// UnmarshalParam decodes and assigns a value from a form, query or path param.
+		UnmarshalParam(param string) error
+	}
+)
+
+// Bind implements the `Binder#Bind` function. Path parameters are bound
+// first to the fields with a `param` tag, followed by query parameters to
+// the fields with a `query` tag, both only if i points to a struct.
+// Finally the request body is decoded according to its Content-Type, form
+// values are bound using the `form` tag or the field name.
+func (b *DefaultBinder) Bind(i interface{}, c Context) (err error) {
+	typ := reflect.TypeOf(i)
+	if typ == nil || typ.Kind() != reflect.Ptr {
+		return errors.New("binding element must be a pointer")
+	}
+
+	if typ.Elem().Kind() == reflect.Struct {
+		params := map[string][]string{}
+		for _, p := range c.Params() {
+			params[p.Key] = []string{p.Value}
+		}
+		if err = b.bindData(i, params, "param"); err != nil {
+			return NewHTTPError(fasthttp.StatusBadRequest, err.Error()).SetInternal(err)
+		}
+
+		if err = b.bindData(i, argsValues(c.QueryParams()), "query"); err != nil {
+			return NewHTTPError(fasthttp.StatusBadRequest, err.Error()).SetInternal(err)
+		}
+	}
+
+	req := &c.Request().Request
+	body := req.Body()
+	if len(body) == 0 {
+		return
+	}
+
+	ctype := string(req.Header.ContentType())
+	switch {
+	case strings.HasPrefix(ctype, MIMEApplicationJSON):
+		if err = json.Unmarshal(body, i); err != nil {
+			return NewHTTPError(fasthttp.StatusBadRequest, err.Error()).SetInternal(err)
+		}
+	case strings.HasPrefix(ctype, MIMEApplicationXML), strings.HasPrefix(ctype, MIMETextXML):
+		if err = xml.Unmarshal(body, i); err != nil {
+			return NewHTTPError(fasthttp.StatusBadRequest, err.Error()).SetInternal(err)
+		}
+	case strings.HasPrefix(ctype, MIMEApplicationForm):
+		if err = b.bindData(i, argsValues(req.PostArgs()), "form"); err != nil {
+			return NewHTTPError(fasthttp.StatusBadRequest, err.Error()).SetInternal(err)
+		}
+	case strings.HasPrefix(ctype, MIMEMultipartForm):
+		form, err := req.MultipartForm()
+		if err != nil {
+			return NewHTTPError(fasthttp.StatusBadRequest, err.Error()).SetInternal(err)
+		}
+		if err = b.bindData(i, form.Value, "form"); err != nil {
+			return NewHTTPError(fasthttp.StatusBadRequest, err.Error()).SetInternal(err)
+		}
+	default:
+		return ErrUnsupportedMediaType
+	}
+	return
+}
+
+// argsValues converts fasthttp arguments into a map of values by key.
+func argsValues(args *fasthttp.Args) map[string][]string {
+	values := make(map[string][]string, args.Len())
+	args.VisitAll(func(key, value []byte) {
+		k := string(key)
+		values[k] = append(values[k], string(value))
+	})
+	return values
+}
+
+func (b *DefaultBinder) bindData(ptr interface{}, data map[string][]string, tag string) error {
+	if ptr == nil || len(data) == 0 {
+		return nil
+	}
+	typ := reflect.TypeOf(ptr).Elem()
+	val := reflect.ValueOf(ptr).Elem()
+
+	// Map
+	if typ.Kind() == reflect.Map {
+		if typ.Key().Kind() != reflect.String {
+			return errors.New("binding element must be a map with string keys")
+		}
+		if val.IsNil() {
+			val.Set(reflect.MakeMap(typ))
+		}
+		for k, v := range data {
+			if typ.Elem().Kind() == reflect.Slice {
+				val.SetMapIndex(reflect.ValueOf(k), reflect.ValueOf(v))
+			} else {
+				val.SetMapIndex(reflect.ValueOf(k), reflect.ValueOf(v[0]))
+			}
+		}
+		return nil
+	}
+
+	// !struct
+	if typ.Kind() != reflect.Struct {
+		return errors.New("binding element must be a struct")
+	}
+
+	for i := 0; i < typ.NumField(); i++ {
+		typeField := typ.Field(i)
+		structField := val.Field(i)
+		if !structField.CanSet() {
+			continue
+		}
+		structFieldKind := structField.Kind()
+		inputFieldName := typeField.Tag.Get(tag)
+
+		if inputFieldName == "" {
+			// If tag is nil, we inspect if the field is a struct.
+			if _, ok := structField.Addr().Interface().(BindUnmarshaler); !ok && structFieldKind == reflect.Struct {
+				if err := b.bindData(structField.Addr().Interface(), data, tag); err != nil {
+					return err
+				}
+				continue
+			}
+			// Path and query parameters are only bound to tagged fields,
+			// otherwise a client could set any field, e.g. ?admin=true
+			if tag != "form" {
+				continue
+			}
+			inputFieldName = typeField.Name
+		} else if inputFieldName == "-" {
+			continue
+		}
+
+		inputValue, exists := data[inputFieldName]
+		if !exists {
+			// json.Unmarshal binds case-insensitive, to be consistent
+			// the map values are searched case-insensitive as well.
+			for k, v := range data {
+				if strings.EqualFold(k, inputFieldName) {
+					inputValue = v
+					exists = true
+					break
+				}
+			}
+		}
+
+		if !exists || len(inputValue) == 0 {
+			continue
+		}
+
+		// Call this first, in case we're dealing with an alias to an array type
+		if ok, err := unmarshalField(inputValue[0], structField); ok {
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		numElems := len(inputValue)
+		if structFieldKind == reflect.Slice {
+			sliceOf := structField.Type().Elem().Kind()
+			slice := reflect.MakeSlice(structField.Type(), numElems, numElems)
+			for j := 0; j < numElems; j++ {
+				if err := setWithProperType(sliceOf, inputValue[j], slice.Index(j)); err != nil {
+					return err
+				}
+			}
+			structField.Set(slice)
+		} else if err := setWithProperType(structFieldKind, inputValue[0], structField); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func setWithProperType(valueKind reflect.Kind, val string, structField reflect.Value) error {
+	// But also call it here, in case we're dealing with an array of BindUnmarshalers
+	if ok, err := unmarshalField(val, structField); ok {
+		return err
+	}
+
+	switch valueKind {
+	case reflect.Ptr:
+		if structField.IsNil() {
+			structField.Set(reflect.New(structField.Type().Elem()))
+		}
+		return setWithProperType(structField.Elem().Kind(), val, structField.Elem())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return setIntField(val, structField)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return setUintField(val, structField)
+	case reflect.Bool:
+		return setBoolField(val, structField)
+	case reflect.Float32, reflect.Float64:
+		return setFloatField(val, structField)
+	case reflect.String:
+		structField.SetString(val)
+	default:
+		return errors.New("unknown type")
+	}
+	return nil
+}
+
+// unmarshalField binds val using BindUnmarshaler or encoding.TextUnmarshaler
+// if implemented by the field. It reports whether one of them was used.
+func unmarshalField(val string, field reflect.Value) (bool, error) {
+	if !field.CanAddr() {
+		return false, nil
+	}
+	ptr := field.Addr()
+	if field.Kind() == reflect.Ptr && field.IsNil() {
+		// Only allocate the value if it implements one of the interfaces
+		v := reflect.New(field.Type().Elem())
+		switch v.Interface().(type) {
+		case BindUnmarshaler, encoding.TextUnmarshaler:
+			field.Set(v)
+		default:
+			return false, nil
+		}
+	}
+	if field.Kind() == reflect.Ptr {
+		ptr = field
+	}
+
+	switch u := ptr.Interface().(type) {
+	case BindUnmarshaler:
+		return true, u.UnmarshalParam(val)
+	case encoding.TextUnmarshaler:
+		return true, u.UnmarshalText([]byte(val))
+	}
+	return false, nil
+}
+
+func setIntField(value string, field reflect.Value) error {
+	if value == "" {
+		value = "0"
+	}
+	intVal, err := strconv.ParseInt(value, 10, field.Type().Bits())
+	if err == nil {
+		field.SetInt(intVal)
+	}
+	return err
+}
+
+func setUintField(value string, field reflect.Value) error {
+	if value == "" {
+		value = "0"
+	}
+	uintVal, err := strconv.ParseUint(value, 10, field.Type().Bits())
+	if err == nil {
+		field.SetUint(uintVal)
+	}
+	return err
+}
+
+func setBoolField(value string, field reflect.Value) error {
+	if value == "" {
+		value = "false"
+	}
+	boolVal, err := strconv.ParseBool(value)
+	if err == nil {
+		field.SetBool(boolVal)
+	}
+	return err
+}
+
+func setFloatField(value string, field reflect.Value) error {
+	if value == "" {
+		value = "0.0"
+	}
+	floatVal, err := strconv.ParseFloat(value, field.Type().Bits())
+	if err == nil {
+		field.SetFloat(floatVal)
+	}
+	return err
+}
@@ -0,0 +1,124 @@
+package enlight
+
+import (
+	"bytes"
+	"mime/multipart"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func newBindContext(e *Enlight, method, uri, contentType string, body []byte) *context {
+	ctx := new(fasthttp.RequestCtx)
+	ctx.Request.Header.SetMethod(method)
+	ctx.Request.SetRequestURI(uri)
+	if contentType != "" {
+		ctx.Request.Header.SetContentType(contentType)
+	}
+	ctx.Request.SetBody(body)
+
+	c := e.NewContext().(*context)
+	c.Reset(ctx)
+	return c
+}
+
+func TestBindBody(t *testing.T) {
+	e := New()
+
+	c := newBindContext(e, "POST", "/users", MIMEApplicationJSON, []byte(userJSON))
+	u := new(user)
+	if assert.NoError(t, c.Bind(u)) {
+		assert.Equal(t, user{ID: 1, Name: "Jon Snow"}, *u)
+	}
+
+	c = newBindContext(e, "POST", "/users", MIMEApplicationXML, []byte(`<user><id>1</id><name>Jon Snow</name></user>`))
+	u = new(user)
+	if assert.NoError(t, c.Bind(u)) {
+		assert.Equal(t, user{ID: 1, Name: "Jon Snow"}, *u)
+	}
+
+	c = newBindContext(e, "POST", "/users", MIMEApplicationForm, []byte(`id=1&name=Jon+Snow`))
+	u = new(user)
+	if assert.NoError(t, c.Bind(u)) {
+		assert.Equal(t, user{ID: 1, Name: "Jon Snow"}, *u)
+	}
+
+	body := new(bytes.Buffer)
+	mw := multipart.NewWriter(body)
+	mw.WriteField("id", "1")
+	mw.WriteField("name", "Jon Snow")
+	mw.Close()
+	c = newBindContext(e, "POST", "/users", mw.FormDataContentType(), body.Bytes())
+	u = new(user)
+	if assert.NoError(t, c.Bind(u)) {
+		assert.Equal(t, user{ID: 1, Name: "Jon Snow"}, *u)
+	}
+}
+
+func TestBindParams(t *testing.T) {
+	e := New()
+
+	c := newBindContext(e, "GET", "/users/1?name=Jon+Snow", "", nil)
+	c.params = Params{{Key: "id", Value: "1"}}
+	u := new(user)
+	if assert.NoError(t, c.Bind(u)) {
+		assert.Equal(t, user{ID: 1, Name: "Jon Snow"}, *u)
+	}
+
+	c = newBindContext(e, "GET", "/search?tag=a&tag=b&limit=10&active=true", "", nil)
+	s := struct {
+		Tags   []string `query:"tag"`
+		Limit  *int     `query:"limit"`
+		Active bool     `query:"active"`
+	}{}
+	if assert.NoError(t, c.Bind(&s)) {
+		assert.Equal(t, []string{"a", "b"}, s.Tags)
+		assert.Equal(t, 10, *s.Limit)
+		assert.True(t, s.Active)
+	}
+
+	// Fields without tag are only bound from the body
+	c = newBindContext(e, "POST", "/users/1?admin=true&Name=Arya", MIMEApplicationJSON, []byte(`{"name":"Jon Snow"}`))
+	c.params = Params{{Key: "id", Value: "1"}}
+	account := struct {
+		ID    int    `param:"id"`
+		Name  string `json:"name"`
+		Admin bool   `json:"-"`
+	}{}
+	if assert.NoError(t, c.Bind(&account)) {
+		assert.Equal(t, 1, account.ID)
+		assert.Equal(t, "Jon Snow", account.Name)
+		assert.False(t, account.Admin)
+	}
+
+	// Slices and other non-structs are bound from the body only
+	c = newBindContext(e, "POST", "/users?notify=true", MIMEApplicationJSON, []byte(`[`+userJSON+`]`))
+	users := []user{}
+	if assert.NoError(t, c.Bind(&users)) {
+		assert.Equal(t, []user{{ID: 1, Name: "Jon Snow"}}, users)
+	}
+}
+
+func TestBindErrors(t *testing.T) {
+	e := New()
+
+	c := newBindContext(e, "POST", "/users", MIMEApplicationJSON, []byte(`{"id":"one"`))
+	err := c.Bind(new(user))
+	if assert.IsType(t, &HTTPError{}, err) {
+		assert.Equal(t, fasthttp.StatusBadRequest, err.(*HTTPError).Code)
+	}
+
+	c = newBindContext(e, "GET", "/users?id=one", "", nil)
+	err = c.Bind(new(user))
+	if assert.IsType(t, &HTTPError{}, err) {
+		assert.Equal(t, fasthttp.StatusBadRequest, err.(*HTTPError).Code)
+	}
+
+	c = newBindContext(e, "POST", "/users", "application/yaml", []byte(`id: 1`))
+	assert.Equal(t, ErrUnsupportedMediaType, c.Bind(new(user)))
+
+	c = newBindContext(e, "POST", "/users", MIMEApplicationJSON, []byte(userJSON))
+	assert.EqualError(t, c.Bind(user{}), "binding element must be a pointer")
+	assert.EqualError(t, c.Bind(nil), "binding element must be a pointer")
+}
@@ -0,0 +1,181 @@
+package enlight
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/valyala/fasthttp"
+	"gopkg.in/yaml.v2"
+)
+
+// ServerConfig defines the options of the HTTP and HTTPS servers. Zero
+// values disable a limit or use the fasthttp default.
+type ServerConfig struct {
+	// Name is sent in the Server response header.
+	// Optional. Default value "Enlight".
+	Name string `yaml:"name"`
+
+	// Concurrency is the maximum number of concurrent connections.
+	// Optional. Default value 256 * 1024.
+	Concurrency int `yaml:"concurrency"`
+
+	// ReadTimeout is the time allowed to read a full request, including
+	// the body. It also applies to idle keep-alive connections unless
+	// IdleTimeout is set.
+	// Optional. Unlimited by default.
+	ReadTimeout time.Duration `yaml:"read_timeout"`
+
+	// WriteTimeout is the time allowed to write a response. Streaming
+	// responses and Server-Sent Events are cut off after it.
+	// Optional. Unlimited by default.
+	WriteTimeout time.Duration `yaml:"write_timeout"`
+
+	// IdleTimeout is the time a keep-alive connection waits for the next
+	// request.
+	// Optional. Default value ReadTimeout.
+	IdleTimeout time.Duration `yaml:"idle_timeout"`
+
+	// ReadBufferSize is the size of the per-connection read buffer, which
+	// limits the size of the request headers.
+	// Optional. Default value 4096.
+	ReadBufferSize int `yaml:"read_buffer_size"`
+
+	// WriteBufferSize is the size of the per-connection write buffer.
+	// Optional. Default value 4096.
+	WriteBufferSize int `yaml:"write_buffer_size"`
+
+	// MaxRequestBodySize is the maximum size of a request body in bytes.
+	// Optional. Default value 4 MB.
+	MaxRequestBodySize int `yaml:"max_request_body_size"`
+
+	// MaxConnsPerIP is the maximum number of concurrent connections of a
+	// client IP.
+	// Optional. Unlimited by default.
+	MaxConnsPerIP int `yaml:"max_conns_per_ip"`
+
+	// MaxRequestsPerConn is the maximum number of requests served on a
+	// connection before it is closed.
+	// Optional. Unlimited by default.
+	MaxRequestsPerConn int `yaml:"max_requests_per_conn"`
+
+	// DisableKeepalive closes connections after every response.
+	// Optional. Default value false.
+	DisableKeepalive bool `yaml:"disable_keepalive"`
+
+	// TCPKeepalive enables TCP keep-alive probes, sent every
+	// TCPKeepalivePeriod.
+	// Optional. Default value false.
+	TCPKeepalive       bool          `yaml:"tcp_keepalive"`
+	TCPKeepalivePeriod time.Duration `yaml:"tcp_keepalive_period"`
+
+	// ReduceMemoryUsage trades CPU for less memory held by idle connections.
+	// Optional. Default value false.
+	ReduceMemoryUsage bool `yaml:"reduce_memory_usage"`
+
+	// ShutdownTimeout is the time a graceful shutdown waits for in-flight
+	// requests.
+	// Optional. Default value 10 seconds.
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+}
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+
+	// DefaultServerConfig is the default server config.
+	DefaultServerConfig = ServerConfig{
+		Name:            "Enlight",
+		ShutdownTimeout: DefaultShutdownTimeout,
+	}
+)
+
+// LoadServerConfig reads a ServerConfig from a YAML or JSON file, unset
+// options keep the values of DefaultServerConfig. Durations are given as
+// strings, e.g. "30s".
+func LoadServerConfig(file string) (ServerConfig, error) {
+	config := DefaultServerConfig
+
+	switch ext := filepath.Ext(file); ext {
+	// JSON is parsed as YAML, which it is a subset of
+	case ".yaml", ".yml", ".json":
+	default:
+		return config, fmt.Errorf("unsupported config format '%s'", ext)
+	}
+
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return config, err
+	}
+	if err = yaml.UnmarshalStrict(b, &config); err != nil {
+		return config, fmt.Errorf("%s: %v", file, err)
+	}
+	return config, nil
+}
+
+// LoadEnv overrides options of the config with environment variables named
+// by prefix and the upper-cased YAML key, e.g. ENLIGHT_READ_TIMEOUT for
+// the prefix "ENLIGHT_".
+func (config *ServerConfig) LoadEnv(prefix string) error {
+	val := reflect.ValueOf(config).Elem()
+	typ := val.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		name := prefix + strings.ToUpper(typ.Field(i).Tag.Get("yaml"))
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			continue
+		}
+
+		field := val.Field(i)
+		if field.Type() == durationType {
+			d, err := time.ParseDuration(value)
+			if err != nil {
+				return fmt.Errorf("%s: %v", name, err)
+			}
+			field.SetInt(int64(d))
+			continue
+		}
+		if err := setWithProperType(field.Kind(), value, field); err != nil {
+			return fmt.Errorf("%s: %v", name, err)
+		}
+	}
+	return nil
+}
+
+// apply sets the options of the config on s.
+func (config ServerConfig) apply(s *fasthttp.Server) {
+	s.Name = config.Name
+	s.Concurrency = config.Concurrency
+	s.ReadTimeout = config.ReadTimeout
+	s.WriteTimeout = config.WriteTimeout
+	s.IdleTimeout = config.IdleTimeout
+	s.ReadBufferSize = config.ReadBufferSize
+	s.WriteBufferSize = config.WriteBufferSize
+	s.MaxRequestBodySize = config.MaxRequestBodySize
+	s.MaxConnsPerIP = config.MaxConnsPerIP
+	s.MaxRequestsPerConn = config.MaxRequestsPerConn
+	s.DisableKeepalive = config.DisableKeepalive
+	s.TCPKeepalive = config.TCPKeepalive
+	s.TCPKeepalivePeriod = config.TCPKeepalivePeriod
+	s.ReduceMemoryUsage = config.ReduceMemoryUsage
+}
+
+// ApplyServerConfig sets the options of config on the Server, the TLSServer
+// and the RedirectServer. It must be called before the server is started.
+func (e *Enlight) ApplyServerConfig(config ServerConfig) {
+	// Defaults
+	if config.Name == "" {
+		config.Name = DefaultServerConfig.Name
+	}
+	if config.ShutdownTimeout == 0 {
+		config.ShutdownTimeout = DefaultServerConfig.ShutdownTimeout
+	}
+
+	config.apply(e.Server)
+	config.apply(e.TLSServer)
+	config.apply(e.RedirectServer)
+	e.ShutdownTimeout = config.ShutdownTimeout
+}
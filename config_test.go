@@ -0,0 +1,100 @@
+package enlight
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func writeConfig(t *testing.T, name, content string) string {
+	dir, err := ioutil.TempDir("", "enlight")
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(file, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func TestLoadServerConfig(t *testing.T) {
+	file := writeConfig(t, "server.yaml", `
+read_timeout: 5s
+write_timeout: 1m
+max_request_body_size: 1048576
+disable_keepalive: true
+`)
+	defer os.RemoveAll(filepath.Dir(file))
+
+	config, err := LoadServerConfig(file)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "Enlight", config.Name)
+		assert.Equal(t, 5*time.Second, config.ReadTimeout)
+		assert.Equal(t, time.Minute, config.WriteTimeout)
+		assert.Equal(t, 1<<20, config.MaxRequestBodySize)
+		assert.True(t, config.DisableKeepalive)
+		assert.Equal(t, DefaultShutdownTimeout, config.ShutdownTimeout)
+	}
+
+	file = writeConfig(t, "server.json", "{\n\t\"name\": \"api\",\n\t\"idle_timeout\": \"90s\",\n\t\"max_conns_per_ip\": 20\n}")
+	defer os.RemoveAll(filepath.Dir(file))
+
+	config, err = LoadServerConfig(file)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "api", config.Name)
+		assert.Equal(t, 90*time.Second, config.IdleTimeout)
+		assert.Equal(t, 20, config.MaxConnsPerIP)
+	}
+
+	// Unknown options are rejected
+	file = writeConfig(t, "server.yml", "read_timout: 5s\n")
+	defer os.RemoveAll(filepath.Dir(file))
+	_, err = LoadServerConfig(file)
+	assert.Error(t, err)
+
+	_, err = LoadServerConfig("server.toml")
+	assert.EqualError(t, err, "unsupported config format '.toml'")
+}
+
+func TestServerConfigLoadEnv(t *testing.T) {
+	os.Setenv("TEST_READ_TIMEOUT", "10s")
+	os.Setenv("TEST_CONCURRENCY", "1000")
+	os.Setenv("TEST_TCP_KEEPALIVE", "true")
+	defer func() {
+		os.Unsetenv("TEST_READ_TIMEOUT")
+		os.Unsetenv("TEST_CONCURRENCY")
+		os.Unsetenv("TEST_TCP_KEEPALIVE")
+	}()
+
+	config := DefaultServerConfig
+	if assert.NoError(t, config.LoadEnv("TEST_")) {
+		assert.Equal(t, 10*time.Second, config.ReadTimeout)
+		assert.Equal(t, 1000, config.Concurrency)
+		assert.True(t, config.TCPKeepalive)
+	}
+
+	os.Setenv("TEST_READ_TIMEOUT", "10")
+	assert.Error(t, config.LoadEnv("TEST_"))
+}
+
+func TestEnlightApplyServerConfig(t *testing.T) {
+	e := New()
+	e.ApplyServerConfig(ServerConfig{
+		ReadTimeout:        5 * time.Second,
+		MaxRequestBodySize: 1024,
+		ShutdownTimeout:    time.Second,
+	})
+
+	for _, s := range []*fasthttp.Server{e.Server, e.TLSServer, e.RedirectServer} {
+		assert.Equal(t, "Enlight", s.Name)
+		assert.Equal(t, 5*time.Second, s.ReadTimeout)
+		assert.Equal(t, 1024, s.MaxRequestBodySize)
+	}
+	assert.Equal(t, time.Second, e.ShutdownTimeout)
+}
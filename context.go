@@ -1,10 +1,20 @@
 package enlight
 
 import (
+	"bufio"
+	"bytes"
+	gocontext "context"
+	"encoding/xml"
 	"mime/multipart"
+	"net"
+	"regexp"
 	"strings"
+	"sync"
+	"time"
 
 	json "github.com/json-iterator/go"
+	"github.com/juliankoehn/enlight/internal/contexts"
+	"github.com/vmihailenco/msgpack/v4"
 
 	"github.com/valyala/fasthttp"
 )
@@ -16,9 +26,41 @@ type (
 		Request() *fasthttp.RequestCtx
 		Response() *fasthttp.Response
 
+		// Path returns the registered path of the matched route, e.g.
+		// "/users/:id". It is set when the route is reached, middleware
+		// added with `Enlight#Use()` can read it after calling next.
+		Path() string
+
+		// Ctx returns the Go context of the request, which is canceled when
+		// the handler returned, the client disconnected or the shutdown of
+		// the server began. It has the deadline set with `Route#Timeout()`.
+		// Pass it to the database and other services, e.g.
+		// `conn.SelectContext(c.Ctx(), ...)`. Disconnects are only noticed on
+		// plain TCP connections on Unix systems, use a timeout to bound the
+		// work of a request anyway.
+		Ctx() gocontext.Context
+
+		// SetCtx replaces the Go context of the request, e.g. by middleware
+		// adding values. ctx should be derived from `Context#Ctx()`.
+		SetCtx(ctx gocontext.Context)
+
+		// RealIP returns the client IP. The X-Forwarded-For and X-Real-IP
+		// headers are only used if the request comes from one of the
+		// `Enlight#TrustedProxies`, as any client can send them.
+		RealIP() string
+
+		// RequestID returns the ID of the request set by the RequestID
+		// middleware, or "" without it. The X-Request-ID header sent by the
+		// client is not used, it is only accepted once validated by the
+		// middleware.
+		RequestID() string
+
 		// Param returns path parameter by name.
 		Param(name string) string
 
+		// Params returns the path parameters of the matched route.
+		Params() Params
+
 		// QueryParamDefault returns the requested Param, if empty returns fallback
 		QueryParamDefault(name string, fallback string) string
 
@@ -36,6 +78,17 @@ type (
 		// FormFile returns FormFile by key or error
 		FormFile(key string) (*multipart.FileHeader, error)
 
+		// Bind binds the request path params, query params and body into
+		// provided type `i`. The default binder decodes the body based on
+		// the Content-Type header.
+		Bind(i interface{}) error
+
+		// Validate validates provided `i` with the registered validator.
+		// It is typically called after `Context#Bind()`. ValidationErrors
+		// are returned as HTTPError with status 422, other errors of the
+		// validator are returned as is and end up as 500.
+		Validate(i interface{}) error
+
 		// Cookie returns value
 		Cookie(key string) string
 
@@ -45,6 +98,10 @@ type (
 		// RemoveCookie removes Cookie by key
 		RemoveCookie(key string)
 
+		// Render renders a template with data and sends a text/html response with status
+		// code. Renderer must be registered using `Enlight.Renderer`.
+		Render(code int, name string, data interface{}) error
+
 		// HTML sends an HTTP response with status code.
 		HTML(code int, html string) error
 
@@ -60,6 +117,38 @@ type (
 		// JSON sends a JSON response with status code.
 		JSON(code int, i interface{}) error
 
+		// JSONPretty sends a pretty-print JSON with status code.
+		JSONPretty(code int, i interface{}, indent string) error
+
+		// JSONP sends a JSONP response with status code. It uses `callback` to construct
+		// the JSONP payload.
+		JSONP(code int, callback string, i interface{}) error
+
+		// XML sends an XML response with status code.
+		XML(code int, i interface{}) error
+
+		// Msgpack sends a msgpack response with status code.
+		Msgpack(code int, i interface{}) error
+
+		// Negotiate sends a response with status code in the format preferred by the
+		// Accept header, which is one of JSON, XML and msgpack. JSON is used if the
+		// request accepts any format.
+		Negotiate(code int, i interface{}) error
+
+		// Stream sends a streaming response with status code and content type.
+		// step is called repeatedly with the response writer until it returns
+		// false, the client disconnected or the shutdown of the server began,
+		// the writer is flushed after every call. The response is streamed
+		// after the handler returned, step must not use the Context.
+		Stream(code int, contentType string, step func(w *bufio.Writer) bool) error
+
+		// SSE starts a Server-Sent Events stream with the DefaultSSEConfig.
+		// Events are sent with the returned EventStream after the handler returned.
+		SSE() *EventStream
+
+		// SSEWithConfig starts a Server-Sent Events stream with config.
+		SSEWithConfig(config SSEConfig) *EventStream
+
 		// File sends a response with the content of the file.
 		File(file string) error
 
@@ -69,6 +158,9 @@ type (
 		// Redirect redirects the request to a provided URL with status code.
 		Redirect(code int, url string) error
 
+		// URLFor generates a URL from the route registered under name.
+		URLFor(name string, params Params) (string, error)
+
 		// Error invokes the registered HTTP error handler. Generally used by middleware.
 		Error(err error)
 
@@ -78,9 +170,33 @@ type (
 		// Peek gets value of key from header or ""
 		Peek(key string) string
 
+		// Get returns the value stored under key or nil.
+		Get(key string) interface{}
+
+		// Set stores val under key for the rest of the request, e.g. to pass
+		// the authenticated user from a middleware to the handler.
+		Set(key string, val interface{})
+
+		// GetString returns the value stored under key if it is a string,
+		// otherwise "".
+		GetString(key string) string
+
+		// GetInt returns the value stored under key if it is an int,
+		// otherwise 0.
+		GetInt(key string) int
+
+		// GetBool returns the value stored under key if it is a bool,
+		// otherwise false.
+		GetBool(key string) bool
+
 		// Enlight returns the `Enlight` instance
 		Enlight() *Enlight
 
+		// Logger returns the logger of the Enlight instance with the method,
+		// path and ID of the request added to every message. A logger
+		// returned before the RequestID middleware ran has no ID.
+		Logger() Logger
+
 		Handler() HandleFunc
 	}
 
@@ -93,6 +209,14 @@ type (
 		query      *fasthttp.Args
 		handler    HandleFunc
 		enlight    *Enlight
+		logger     Logger
+		ctx        gocontext.Context
+		cancel     gocontext.CancelFunc
+		stopWatch  chan struct{}
+		timeout    time.Duration
+		stream     *EventStream
+		store      Map
+		lock       sync.RWMutex
 	}
 )
 
@@ -101,6 +225,18 @@ const (
 	indexPage     = "index.html"
 )
 
+var (
+	// Offers of Negotiate, ordered by preference
+	negotiateOffers = []string{
+		MIMEApplicationJSON,
+		MIMEApplicationXML,
+		MIMETextXML,
+		MIMEApplicationMsgpack,
+	}
+
+	jsonpCallbackRegex = regexp.MustCompile(`^[a-zA-Z_$][a-zA-Z0-9_$.]*$`)
+)
+
 // WantsJSON checks if contentType or Accept header contains "application/json"
 func (c *context) WantsJSON() bool {
 	contentType := c.Peek("Content-Type")
@@ -128,10 +264,82 @@ func (c *context) Request() *fasthttp.RequestCtx {
 	return c.RequestCtx
 }
 
+func (c *context) Path() string {
+	return c.path
+}
+
+func (c *context) Ctx() gocontext.Context {
+	if c.ctx == nil {
+		parent := c.enlight.requestContext()
+		if c.timeout > 0 {
+			c.ctx, c.cancel = gocontext.WithTimeout(parent, c.timeout)
+		} else {
+			c.ctx, c.cancel = gocontext.WithCancel(parent)
+		}
+		c.watchDisconnect()
+	}
+	return c.ctx
+}
+
+func (c *context) SetCtx(ctx gocontext.Context) {
+	// The cancel func of the replaced context is kept, so it is still
+	// released at the end of the request
+	c.ctx = ctx
+}
+
+// setTimeout sets the timeout of the Go context. A context created before,
+// e.g. by a middleware, gets the deadline too.
+func (c *context) setTimeout(timeout time.Duration) {
+	c.timeout = timeout
+	if c.ctx == nil {
+		return
+	}
+	ctx, cancel := gocontext.WithTimeout(c.ctx, timeout)
+	if parentCancel := c.cancel; parentCancel != nil {
+		c.cancel = func() {
+			cancel()
+			parentCancel()
+		}
+	} else {
+		c.cancel = cancel
+	}
+	c.ctx = ctx
+}
+
+func (c *context) RealIP() string {
+	remote := c.RequestCtx.RemoteIP()
+	if !c.enlight.IsTrustedProxy(remote) {
+		return remote.String()
+	}
+	// Every proxy appends the address it got the request from, so the
+	// client is the last address not added by a trusted proxy
+	if forwarded := c.Peek(HeaderXForwardedFor); forwarded != "" {
+		ips := strings.Split(forwarded, ",")
+		for i := len(ips) - 1; i >= 0; i-- {
+			ip := strings.TrimSpace(ips[i])
+			if i == 0 || !c.enlight.IsTrustedProxy(net.ParseIP(ip)) {
+				return ip
+			}
+		}
+	}
+	if ip := c.Peek(HeaderXRealIP); ip != "" {
+		return ip
+	}
+	return remote.String()
+}
+
+func (c *context) RequestID() string {
+	return c.GetString(ContextKeyRequestID)
+}
+
 func (c *context) Param(name string) string {
 	return c.params.ByName(name)
 }
 
+func (c *context) Params() Params {
+	return c.params
+}
+
 func (c *context) ParamNames() []string {
 	return c.pnames
 }
@@ -176,6 +384,28 @@ func (c *context) FormFile(key string) (*multipart.FileHeader, error) {
 	return c.RequestCtx.FormFile(key)
 }
 
+func (c *context) Bind(i interface{}) error {
+	return c.enlight.Binder.Bind(i, c)
+}
+
+func (c *context) Validate(i interface{}) error {
+	if c.enlight.Validator == nil {
+		return ErrValidatorNotRegistered
+	}
+	if err := c.enlight.Validator.Validate(i); err != nil {
+		switch e := err.(type) {
+		case *HTTPError:
+			return e
+		case ValidationErrors:
+			return NewHTTPError(fasthttp.StatusUnprocessableEntity, e).SetInternal(err)
+		default:
+			// Not a mistake of the client, e.g. validating a non-struct
+			return err
+		}
+	}
+	return nil
+}
+
 // Cookie
 
 func (c *context) Cookie(key string) string {
@@ -195,6 +425,17 @@ func (c *context) RemoveCookie(key string) {
 
 // Responses
 
+func (c *context) Render(code int, name string, data interface{}) (err error) {
+	if c.enlight.Renderer == nil {
+		return ErrRendererNotRegistered
+	}
+	buf := new(bytes.Buffer)
+	if err = c.enlight.Renderer.Render(buf, name, data, c); err != nil {
+		return
+	}
+	return c.HTMLBlob(code, buf.Bytes())
+}
+
 func (c *context) HTML(code int, html string) (err error) {
 	return c.HTMLBlob(code, []byte(html))
 }
@@ -225,12 +466,19 @@ func (c *context) Redirect(code int, url string) error {
 	return nil
 }
 
+func (c *context) URLFor(name string, params Params) (string, error) {
+	return c.enlight.URL(name, params)
+}
+
 func (c *context) String(code int, s string) (err error) {
 	return c.Blob(code, MIMETextPlainCharsetUTF8, []byte(s))
 }
 
-func (c *context) json(code int, i interface{}) error {
+func (c *context) json(code int, i interface{}, indent string) error {
 	enc := json.NewEncoder(c.RequestCtx)
+	if indent != "" {
+		enc.SetIndent("", indent)
+	}
 
 	c.RequestCtx.SetContentType(MIMEApplicationJSONCharsetUTF8)
 	c.RequestCtx.Response.SetStatusCode(code)
@@ -239,7 +487,113 @@ func (c *context) json(code int, i interface{}) error {
 }
 
 func (c *context) JSON(code int, i interface{}) (err error) {
-	return c.json(code, i)
+	return c.json(code, i, "")
+}
+
+func (c *context) JSONPretty(code int, i interface{}, indent string) (err error) {
+	return c.json(code, i, indent)
+}
+
+func (c *context) JSONP(code int, callback string, i interface{}) (err error) {
+	if !jsonpCallbackRegex.MatchString(callback) {
+		return ErrInvalidJSONPCallback
+	}
+	b, err := json.Marshal(i)
+	if err != nil {
+		return
+	}
+
+	buf := make([]byte, 0, len(callback)+len(b)+3)
+	buf = append(buf, callback...)
+	buf = append(buf, '(')
+	buf = append(buf, b...)
+	buf = append(buf, ");"...)
+	return c.Blob(code, MIMEApplicationJavaScriptCharsetUTF8, buf)
+}
+
+func (c *context) XML(code int, i interface{}) (err error) {
+	c.RequestCtx.SetContentType(MIMEApplicationXMLCharsetUTF8)
+	c.RequestCtx.Response.SetStatusCode(code)
+	if _, err = c.RequestCtx.WriteString(xml.Header); err != nil {
+		return
+	}
+	return xml.NewEncoder(c.RequestCtx).Encode(i)
+}
+
+func (c *context) Msgpack(code int, i interface{}) (err error) {
+	b, err := msgpack.Marshal(i)
+	if err != nil {
+		return
+	}
+	return c.Blob(code, MIMEApplicationMsgpack, b)
+}
+
+func (c *context) Negotiate(code int, i interface{}) error {
+	switch NegotiateContentType(c.Peek(HeaderAccept), negotiateOffers) {
+	case MIMEApplicationJSON:
+		return c.JSON(code, i)
+	case MIMEApplicationXML, MIMETextXML:
+		return c.XML(code, i)
+	case MIMEApplicationMsgpack:
+		return c.Msgpack(code, i)
+	}
+	return ErrNotAcceptable
+}
+
+func (c *context) Stream(code int, contentType string, step func(w *bufio.Writer) bool) error {
+	c.RequestCtx.SetContentType(contentType)
+	c.RequestCtx.SetStatusCode(code)
+	// An idle keep-alive connection would hold the shutdown once the
+	// stream ended, streams are long-lived anyway
+	c.RequestCtx.SetConnectionClose()
+	shutdown := c.enlight.requestContext().Done()
+	c.RequestCtx.SetBodyStreamWriter(func(w *bufio.Writer) {
+		for step(w) {
+			// Flushing fails once the client disconnected
+			if err := w.Flush(); err != nil {
+				return
+			}
+			// End the response, so the shutdown does not wait for it
+			select {
+			case <-shutdown:
+				return
+			default:
+			}
+		}
+	})
+	return nil
+}
+
+func (c *context) SSE() *EventStream {
+	return c.SSEWithConfig(DefaultSSEConfig)
+}
+
+func (c *context) SSEWithConfig(config SSEConfig) *EventStream {
+	// Defaults
+	if config.BufferSize == 0 {
+		config.BufferSize = DefaultSSEConfig.BufferSize
+	}
+
+	s := &EventStream{
+		config:      config,
+		lastEventID: c.Peek(HeaderLastEventID),
+		events:      make(chan *Event, config.BufferSize),
+		shutdown:    c.enlight.requestContext().Done(),
+		closing:     make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+
+	c.RequestCtx.Response.Header.Set(HeaderCacheControl, "no-cache")
+	// Disable response buffering of nginx
+	c.RequestCtx.Response.Header.Set("X-Accel-Buffering", "no")
+	c.RequestCtx.SetContentType(MIMETextEventStream)
+	c.RequestCtx.SetStatusCode(fasthttp.StatusOK)
+	// Clients reconnect on a new connection anyway, and an idle keep-alive
+	// connection would hold the shutdown
+	c.RequestCtx.SetConnectionClose()
+	c.RequestCtx.SetBodyStreamWriter(s.run)
+	c.stream = s
+	return s
 }
 
 func (c *context) File(file string) (err error) {
@@ -251,10 +605,123 @@ func (c *context) Error(err error) {
 	c.enlight.HTTPErrorHandler(err, c)
 }
 
+func (c *context) Get(key string) interface{} {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.store[key]
+}
+
+func (c *context) Set(key string, val interface{}) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.store == nil {
+		c.store = make(Map)
+	}
+	c.store[key] = val
+}
+
+func (c *context) GetString(key string) string {
+	s, _ := c.Get(key).(string)
+	return s
+}
+
+func (c *context) GetInt(key string) int {
+	i, _ := c.Get(key).(int)
+	return i
+}
+
+func (c *context) GetBool(key string) bool {
+	b, _ := c.Get(key).(bool)
+	return b
+}
+
 func (c *context) Enlight() *Enlight {
 	return c.enlight
 }
 
+func (c *context) Logger() Logger {
+	if c.logger == nil {
+		fields := []interface{}{
+			"method", string(c.RequestCtx.Method()),
+			"path", string(c.RequestCtx.Path()),
+		}
+		id := c.RequestID()
+		if id == "" {
+			// Not cached, the RequestID middleware may not have run yet
+			return c.enlight.Logger.With(fields...)
+		}
+		c.logger = c.enlight.Logger.With(append(fields, "request_id", id)...)
+	}
+	return c.logger
+}
+
+func init() {
+	contexts.Detach = func(c interface{}) interface{} {
+		return c.(*context).detach()
+	}
+	contexts.Merge = func(c, detached interface{}) {
+		c.(*context).merge(detached.(*context))
+	}
+}
+
+// merge copies the response, the route path and the stored values of a
+// context returned by detach back to the context. The detached context must
+// not be used concurrently.
+func (c *context) merge(d *context) {
+	d.RequestCtx.Response.CopyTo(&c.RequestCtx.Response)
+	c.path = d.path
+	d.lock.RLock()
+	for k, v := range d.store {
+		c.Set(k, v)
+	}
+	d.lock.RUnlock()
+}
+
+// detach returns a copy of the context with its own copy of the request and
+// response, which stays valid after the request has been handled.
+func (c *context) detach() *context {
+	ctx := new(fasthttp.RequestCtx)
+	ctx.Init(&c.RequestCtx.Request, c.RequestCtx.RemoteAddr(), nil)
+	c.RequestCtx.Response.CopyTo(&ctx.Response)
+
+	d := &context{
+		RequestCtx: ctx,
+		path:       c.path,
+		pnames:     c.pnames,
+		handler:    c.handler,
+		enlight:    c.enlight,
+		timeout:    c.timeout,
+	}
+	if c.params != nil {
+		d.params = append(Params(nil), c.params...)
+	}
+	c.lock.RLock()
+	for k, v := range c.store {
+		d.Set(k, v)
+	}
+	c.lock.RUnlock()
+	return d
+}
+
+// release cancels the Go context of the request and ends an event stream
+// whose response was replaced, e.g. by the error handler.
+func (c *context) release() {
+	if c.stream != nil && !c.RequestCtx.Response.IsBodyStream() {
+		c.stream.end()
+	}
+	c.stream = nil
+
+	if c.stopWatch != nil {
+		close(c.stopWatch)
+	}
+	if c.cancel != nil {
+		c.cancel()
+	}
+	c.ctx = nil
+	c.cancel = nil
+	c.stopWatch = nil
+}
+
 // func (c *context) Reset(r *http.Request, w http.ResponseWriter) {
 func (c *context) Reset(ctx *fasthttp.RequestCtx) {
 	//c.request = r
@@ -265,4 +732,13 @@ func (c *context) Reset(ctx *fasthttp.RequestCtx) {
 	c.path = ""
 	c.pnames = nil
 	c.params = nil
+	c.logger = nil
+	c.timeout = 0
+	c.release()
+	// Keep the map of the pooled context, but drop the values
+	c.lock.Lock()
+	for k := range c.store {
+		delete(c.store, k)
+	}
+	c.lock.Unlock()
 }
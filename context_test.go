@@ -1,9 +1,15 @@
 package enlight
 
 import (
+	gocontext "context"
+	"encoding/xml"
+	"net"
 	"testing"
+	"time"
 
 	testify "github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+	"github.com/vmihailenco/msgpack/v4"
 )
 
 func TestContext(t *testing.T) {
@@ -12,3 +18,155 @@ func TestContext(t *testing.T) {
 	assert := testify.New(t)
 	assert.Equal(e, c.Enlight())
 }
+
+func TestContextStore(t *testing.T) {
+	e := New()
+	assert := testify.New(t)
+	c := e.NewContext().(*context)
+	c.Reset(new(fasthttp.RequestCtx))
+
+	c.Set("user", "jon")
+	c.Set("id", 42)
+	c.Set("admin", true)
+	assert.Equal("jon", c.Get("user"))
+	assert.Equal("jon", c.GetString("user"))
+	assert.Equal(42, c.GetInt("id"))
+	assert.True(c.GetBool("admin"))
+	assert.Nil(c.Get("missing"))
+	assert.Equal("", c.GetString("id"))
+	assert.Equal(0, c.GetInt("user"))
+
+	// Values do not leak to the next request of a pooled context
+	c.Reset(new(fasthttp.RequestCtx))
+	assert.Nil(c.Get("user"))
+	assert.Equal(0, c.GetInt("id"))
+}
+
+func TestContextRealIP(t *testing.T) {
+	e := New()
+	assert := testify.New(t)
+	_, private, _ := net.ParseCIDR("10.0.0.0/8")
+
+	realIP := func(remote net.IP, headers ...string) string {
+		req := new(fasthttp.Request)
+		for i := 0; i+1 < len(headers); i += 2 {
+			req.Header.Set(headers[i], headers[i+1])
+		}
+		ctx := new(fasthttp.RequestCtx)
+		ctx.Init(req, &net.TCPAddr{IP: remote}, nil)
+		c := e.NewContext().(*context)
+		c.Reset(ctx)
+		return c.RealIP()
+	}
+
+	// Headers of untrusted clients are ignored
+	assert.Equal("203.0.113.7", realIP(net.IPv4(203, 0, 113, 7), HeaderXForwardedFor, "1.2.3.4", HeaderXRealIP, "1.2.3.4"))
+	assert.Equal("10.0.0.1", realIP(net.IPv4(10, 0, 0, 1), HeaderXForwardedFor, "1.2.3.4"))
+
+	e.TrustedProxies = []*net.IPNet{private}
+	assert.Equal("203.0.113.7", realIP(net.IPv4(10, 0, 0, 1), HeaderXForwardedFor, "1.2.3.4, 203.0.113.7, 10.0.0.2"))
+	assert.Equal("1.2.3.4", realIP(net.IPv4(10, 0, 0, 1), HeaderXForwardedFor, "1.2.3.4"))
+	assert.Equal("1.2.3.4", realIP(net.IPv4(10, 0, 0, 1), HeaderXRealIP, "1.2.3.4"))
+	assert.Equal("10.0.0.1", realIP(net.IPv4(10, 0, 0, 1)))
+	assert.Equal("203.0.113.7", realIP(net.IPv4(203, 0, 113, 7), HeaderXForwardedFor, "1.2.3.4"))
+}
+
+func TestContextCtx(t *testing.T) {
+	e := New()
+	assert := testify.New(t)
+
+	var reqCtx gocontext.Context
+	var deadline time.Time
+	e.GET("/slow", func(c Context) error {
+		reqCtx = c.Ctx()
+		deadline, _ = reqCtx.Deadline()
+		c.SetCtx(gocontext.WithValue(c.Ctx(), "tenant", "north"))
+		assert.Equal("north", c.Ctx().Value("tenant"))
+		return nil
+	}).Timeout(time.Minute)
+
+	ctx := new(fasthttp.RequestCtx)
+	ctx.Request.SetRequestURI("/slow")
+	e.ServeHTTP(ctx)
+	assert.WithinDuration(time.Now().Add(time.Minute), deadline, time.Second)
+	// The context is canceled once the request has been handled
+	assert.Equal(gocontext.Canceled, reqCtx.Err())
+
+	e.GET("/fast", func(c Context) error {
+		_, ok := c.Ctx().Deadline()
+		assert.False(ok)
+		return nil
+	})
+	ctx = new(fasthttp.RequestCtx)
+	ctx.Request.SetRequestURI("/fast")
+	e.ServeHTTP(ctx)
+}
+
+func TestContextResponses(t *testing.T) {
+	e := New()
+	assert := testify.New(t)
+	u := user{ID: 1, Name: "Jon Snow"}
+
+	newContext := func(accept string) *context {
+		ctx := new(fasthttp.RequestCtx)
+		ctx.Request.Header.Set(HeaderAccept, accept)
+		c := e.NewContext().(*context)
+		c.Reset(ctx)
+		return c
+	}
+
+	c := newContext("")
+	if assert.NoError(c.XML(200, u)) {
+		assert.Equal(MIMEApplicationXMLCharsetUTF8, string(c.Response().Header.ContentType()))
+		assert.Equal(xml.Header+`<user><id>1</id><name>Jon Snow</name></user>`, string(c.Response().Body()))
+	}
+
+	c = newContext("")
+	if assert.NoError(c.JSONPretty(200, u, "  ")) {
+		assert.Equal("{\n  \"id\": 1,\n  \"name\": \"Jon Snow\"\n}\n", string(c.Response().Body()))
+	}
+
+	c = newContext("")
+	if assert.NoError(c.JSONP(200, "callback", u)) {
+		assert.Equal(MIMEApplicationJavaScriptCharsetUTF8, string(c.Response().Header.ContentType()))
+		assert.Equal(`callback(`+userJSON+`);`, string(c.Response().Body()))
+	}
+	assert.Equal(ErrInvalidJSONPCallback, newContext("").JSONP(200, "alert(1)//", u))
+
+	c = newContext("")
+	if assert.NoError(c.Msgpack(200, u)) {
+		var decoded user
+		assert.Equal(MIMEApplicationMsgpack, string(c.Response().Header.ContentType()))
+		assert.NoError(msgpack.Unmarshal(c.Response().Body(), &decoded))
+		assert.Equal(u, decoded)
+	}
+}
+
+func TestContextNegotiate(t *testing.T) {
+	e := New()
+	assert := testify.New(t)
+
+	tests := map[string]string{
+		"":    MIMEApplicationJSONCharsetUTF8,
+		"*/*": MIMEApplicationJSONCharsetUTF8,
+		"text/html, application/xml;q=0.9, */*;q=0.8": MIMEApplicationXMLCharsetUTF8,
+		"application/json;q=0.5, text/xml":            MIMEApplicationXMLCharsetUTF8,
+		"application/*;q=0.2, application/msgpack":    MIMEApplicationMsgpack,
+		"application/*, application/json;q=0":         MIMEApplicationXMLCharsetUTF8,
+	}
+	for accept, contentType := range tests {
+		ctx := new(fasthttp.RequestCtx)
+		ctx.Request.Header.Set(HeaderAccept, accept)
+		c := e.NewContext().(*context)
+		c.Reset(ctx)
+		if assert.NoError(c.Negotiate(200, user{ID: 1}), accept) {
+			assert.Equal(contentType, string(c.Response().Header.ContentType()), accept)
+		}
+	}
+
+	ctx := new(fasthttp.RequestCtx)
+	ctx.Request.Header.Set(HeaderAccept, "text/html")
+	c := e.NewContext().(*context)
+	c.Reset(ctx)
+	assert.Equal(ErrNotAcceptable, c.Negotiate(200, user{ID: 1}))
+}
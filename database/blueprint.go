@@ -1,7 +1,6 @@
 package database
 
 import (
-	"fmt"
 	"strings"
 )
 
@@ -576,7 +575,9 @@ func (b *Blueprint) Execute(conn Connection) []string {
 	statements := b.toSQL(&conn, grammar)
 
 	for _, statement := range statements {
-		fmt.Println(statement)
+		if conn.Logger != nil {
+			conn.Logger.Debug("executing statement", "sql", statement)
+		}
 		if _, err := conn.Exec(statement); err != nil {
 			panic(err)
 		}
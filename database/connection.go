@@ -1,6 +1,7 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"reflect"
 	"strings"
@@ -19,6 +20,13 @@ type (
 		config       ConnectionConfig
 		unsafe       bool
 		Mapper       *reflectx.Mapper
+		Logger       Logger
+	}
+
+	// Logger is the interface executed statements are logged to. It is
+	// satisfied by `enlight.Logger`.
+	Logger interface {
+		Debug(msg string, fields ...interface{})
 	}
 
 	// Connections holds connections Key:Value
@@ -105,6 +113,18 @@ func (c *Connection) QueryRow(query string, args ...interface{}) *Row {
 	return &Row{rows: rows, err: err, unsafe: c.unsafe, Mapper: c.Mapper}
 }
 
+// SelectContext selects using this DB, the query is canceled when ctx is done.
+func (c *Connection) SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	return SelectContext(ctx, c, dest, query, args...)
+}
+
+// QueryRowContext selects one row from database, the query is canceled when
+// ctx is done.
+func (c *Connection) QueryRowContext(ctx context.Context, query string, args ...interface{}) *Row {
+	rows, err := c.DB.QueryContext(ctx, query, args...)
+	return &Row{rows: rows, err: err, unsafe: c.unsafe, Mapper: c.Mapper}
+}
+
 func (c *Connection) Run(query string, dest interface{}, args ...interface{}) error {
 	// reconnectIfMissingConnection
 	rows, err := c.DB.Query(query, args...)
@@ -11,6 +11,8 @@ type (
 		config      Config
 		Factory     *factory
 		Connections Connections
+		// Logger is set on the connections made by the Manager.
+		Logger Logger
 	}
 
 	// ConnectionConfig holds Connection Options for a Database
@@ -93,6 +95,18 @@ func (m *Manager) Disconnect(name string) error {
 	return fmt.Errorf("connection with name [%s] not found", name)
 }
 
+// Close closes all open connections of the Manager. It returns the first
+// error, all connections are closed regardless.
+func (m *Manager) Close() (err error) {
+	for name, conn := range m.Connections {
+		if cerr := conn.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+		delete(m.Connections, name)
+	}
+	return
+}
+
 // Reconnect to given database
 func (m *Manager) Reconnect(name string) (*Connection, error) {
 	if name == "" {
@@ -149,6 +163,7 @@ func (m *Manager) makeConnection(name string) (*Connection, error) {
 	}
 
 	conn.config = *config
+	conn.Logger = m.Logger
 
 	return conn, nil
 }
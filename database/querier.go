@@ -1,6 +1,7 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"database/sql/driver"
 	"errors"
@@ -15,6 +16,10 @@ type (
 	Queryer interface {
 		Query(query string, args ...interface{}) (*sql.Rows, error)
 	}
+	// QueryerContext is an interface used by SelectContext
+	QueryerContext interface {
+		QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	}
 	rowsi interface {
 		Close() error
 		Columns() ([]string, error)
@@ -92,6 +97,18 @@ func Select(q Queryer, dest interface{}, query string, args ...interface{}) erro
 	return scanAll(rows, dest, false)
 }
 
+// SelectContext executes a query using the provided QueryerContext like
+// Select. The query is canceled when ctx is done.
+func SelectContext(ctx context.Context, q QueryerContext, dest interface{}, query string, args ...interface{}) error {
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	return scanAll(rows, dest, false)
+}
+
 // scanAll scans all rows into a destination, which must be a slice of any
 // type.  If the destination slice type is a Struct, then StructScan will be
 // used on each row.  If the destination is some other kind of base type, then
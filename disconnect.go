@@ -0,0 +1,55 @@
+//go:build linux || darwin || dragonfly || freebsd || netbsd || openbsd
+// +build linux darwin dragonfly freebsd netbsd openbsd
+
+package enlight
+
+import (
+	"syscall"
+	"time"
+)
+
+// disconnectInterval is the time between two checks of the connection of
+// a request whose Go context is in use.
+var disconnectInterval = 100 * time.Millisecond
+
+// watchDisconnect cancels the Go context of the request once the client
+// closed the connection. The socket is peeked without reading from it
+// until the request is released. Only plain TCP connections are watched,
+// TLS connections and those wrapped by the server for MaxConnsPerIP are not.
+func (c *context) watchDisconnect() {
+	conn, ok := c.RequestCtx.Conn().(syscall.Conn)
+	if !ok {
+		return
+	}
+	raw, err := conn.SyscallConn()
+	if err != nil {
+		return
+	}
+
+	cancel, stop := c.cancel, make(chan struct{})
+	c.stopWatch = stop
+	go func() {
+		ticker := time.NewTicker(disconnectInterval)
+		defer ticker.Stop()
+		buf := make([]byte, 1)
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+
+			closed := false
+			raw.Control(func(fd uintptr) {
+				n, _, err := syscall.Recvfrom(int(fd), buf, syscall.MSG_PEEK|syscall.MSG_DONTWAIT)
+				// A read of 0 bytes is the end of the stream, pipelined
+				// requests or EAGAIN mean the client is still there
+				closed = n == 0 && err == nil || err == syscall.ECONNRESET
+			})
+			if closed {
+				cancel()
+				return
+			}
+		}
+	}()
+}
@@ -0,0 +1,8 @@
+//go:build !linux && !darwin && !dragonfly && !freebsd && !netbsd && !openbsd
+// +build !linux,!darwin,!dragonfly,!freebsd,!netbsd,!openbsd
+
+package enlight
+
+// watchDisconnect is not supported on this platform, the Go context of a
+// request is not canceled when the client disconnects.
+func (c *context) watchDisconnect() {}
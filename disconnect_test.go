@@ -0,0 +1,45 @@
+//go:build linux || darwin || dragonfly || freebsd || netbsd || openbsd
+// +build linux darwin dragonfly freebsd netbsd openbsd
+
+package enlight
+
+import (
+	ctx "context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContextDisconnect(t *testing.T) {
+	e := New()
+	e.HideBanner = true
+	started := make(chan struct{})
+	canceled := make(chan error, 1)
+	e.GET("/wait", func(c Context) error {
+		close(started)
+		select {
+		case <-c.Ctx().Done():
+			canceled <- c.Ctx().Err()
+		case <-time.After(2 * time.Second):
+			canceled <- nil
+		}
+		return nil
+	})
+
+	address := freeAddress(t)
+	go e.Start(address)
+	defer e.Shutdown()
+	time.Sleep(100 * time.Millisecond)
+
+	conn, err := net.Dial("tcp", address)
+	if !assert.NoError(t, err) {
+		return
+	}
+	conn.Write([]byte("GET /wait HTTP/1.1\r\nHost: test\r\n\r\n"))
+	<-started
+	// The client gives up before the response is written
+	conn.Close()
+	assert.Equal(t, ctx.Canceled, <-canceled)
+}
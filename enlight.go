@@ -1,13 +1,22 @@
 package enlight
 
 import (
-	"fmt"
+	gocontext "context"
+	"crypto/tls"
 	"io"
+	"net"
 	"net/url"
+	"os"
+	"os/signal"
 	"path"
 	"path/filepath"
+	"reflect"
+	"runtime"
 	"sync"
+	"syscall"
+	"time"
 
+	json "github.com/json-iterator/go"
 	"github.com/valyala/fasthttp"
 )
 
@@ -20,15 +29,40 @@ type Enlight struct {
 	Router           *Router
 	Server           *fasthttp.Server
 	TLSServer        *fasthttp.Server
+	RedirectServer   *fasthttp.Server
 	premiddleware    []MiddlewareFunc
 	aftermiddleware  []MiddlewareFunc
 	middleware       []MiddlewareFunc
 	HTTPErrorHandler HTTPErrorHandler
+	Binder           Binder
+	Validator        Validator
 	pool             sync.Pool
 	Renderer         Renderer
+	Logger           Logger
+	HideBanner       bool
+
+	// TrustedProxies are the networks of the proxies whose X-Forwarded-For
+	// and X-Real-IP headers are used by `Context#RealIP()`.
+	TrustedProxies []*net.IPNet
+
+	// ShutdownTimeout is the time `Enlight#Shutdown()` and
+	// `Enlight#StartWithContext()` wait for in-flight requests.
+	ShutdownTimeout time.Duration
+
+	certificates []tls.Certificate
+
+	// requestCtx is the parent of the Go contexts of requests, it is
+	// canceled when the shutdown begins and renewed on start
+	requestCtx     gocontext.Context
+	cancelRequests gocontext.CancelFunc
+	requestMutex   sync.RWMutex
+
+	onStart            []Hook
+	onShutdown         []Hook
+	onShutdownComplete []Hook
 }
 
-// Common struct for Echo & Group.
+// common struct for Enlight & Group.
 type common struct{}
 
 // Renderer is the interface that wraps the Render function.
@@ -36,19 +70,32 @@ type Renderer interface {
 	Render(io.Writer, string, interface{}, Context) error
 }
 
+// Hook is a function run at a stage of the server lifecycle.
+type Hook func(ctx gocontext.Context) error
+
+// DefaultShutdownTimeout is the default time a graceful shutdown waits for
+// in-flight requests.
+const DefaultShutdownTimeout = 10 * time.Second
+
 // Map defines a generic map of type `map[string]interface{}`.
 type Map map[string]interface{}
 
 // New returns a new initialized Enlight instance
 func New() (e *Enlight) {
 	e = &Enlight{
-		Server:           new(fasthttp.Server),
-		TLSServer:        new(fasthttp.Server),
+		Server:           &fasthttp.Server{Name: "Enlight"},
+		TLSServer:        &fasthttp.Server{Name: "Enlight"},
+		RedirectServer:   &fasthttp.Server{Name: "Enlight"},
 		Router:           NewRouter(),
 		HTTPErrorHandler: e.DefaultHTTPErrorHandler,
+		Binder:           &DefaultBinder{},
+		Validator:        NewValidator(),
+		Logger:           NewLogger(os.Stdout),
 		Debug:            false,
+		ShutdownTimeout:  DefaultShutdownTimeout,
 	}
 	e.Server.Handler = e.ServeHTTP
+	e.TLSServer.Handler = e.ServeHTTP
 	e.pool.New = func() interface{} {
 		return e.NewContext()
 	}
@@ -79,48 +126,48 @@ func (e *Enlight) Use(middleware ...MiddlewareFunc) {
 }
 
 // CONNECT registers a new CONNECT route for a path with matching handler
-func (e *Enlight) CONNECT(path string, handle HandleFunc, m ...MiddlewareFunc) {
-	e.Add(fasthttp.MethodConnect, path, handle, m...)
+func (e *Enlight) CONNECT(path string, handle HandleFunc, m ...MiddlewareFunc) *Route {
+	return e.Add(fasthttp.MethodConnect, path, handle, m...)
 }
 
 // DELETE registers a new DELETE route for a path with matching handler
-func (e *Enlight) DELETE(path string, handle HandleFunc, m ...MiddlewareFunc) {
-	e.Add(fasthttp.MethodDelete, path, handle, m...)
+func (e *Enlight) DELETE(path string, handle HandleFunc, m ...MiddlewareFunc) *Route {
+	return e.Add(fasthttp.MethodDelete, path, handle, m...)
 }
 
 // GET registers a new GET route for a path withh matching handler
-func (e *Enlight) GET(path string, handle HandleFunc, m ...MiddlewareFunc) {
-	e.Add(fasthttp.MethodGet, path, handle, m...)
+func (e *Enlight) GET(path string, handle HandleFunc, m ...MiddlewareFunc) *Route {
+	return e.Add(fasthttp.MethodGet, path, handle, m...)
 }
 
 // HEAD registers a new HEAD route for a path withh matching handler
-func (e *Enlight) HEAD(path string, handle HandleFunc, m ...MiddlewareFunc) {
-	e.Add(fasthttp.MethodHead, path, handle, m...)
+func (e *Enlight) HEAD(path string, handle HandleFunc, m ...MiddlewareFunc) *Route {
+	return e.Add(fasthttp.MethodHead, path, handle, m...)
 }
 
 // OPTIONS registers a new OPTIONS route for a path withh matching handler
-func (e *Enlight) OPTIONS(path string, handle HandleFunc, m ...MiddlewareFunc) {
-	e.Add(fasthttp.MethodOptions, path, handle, m...)
+func (e *Enlight) OPTIONS(path string, handle HandleFunc, m ...MiddlewareFunc) *Route {
+	return e.Add(fasthttp.MethodOptions, path, handle, m...)
 }
 
 // PATCH registers a new PATCH route for a path with matching handler
-func (e *Enlight) PATCH(path string, handle HandleFunc, m ...MiddlewareFunc) {
-	e.Add(fasthttp.MethodPatch, path, handle, m...)
+func (e *Enlight) PATCH(path string, handle HandleFunc, m ...MiddlewareFunc) *Route {
+	return e.Add(fasthttp.MethodPatch, path, handle, m...)
 }
 
 // POST registers a new POST route for a path with matching handler
-func (e *Enlight) POST(path string, handle HandleFunc, m ...MiddlewareFunc) {
-	e.Add(fasthttp.MethodPost, path, handle, m...)
+func (e *Enlight) POST(path string, handle HandleFunc, m ...MiddlewareFunc) *Route {
+	return e.Add(fasthttp.MethodPost, path, handle, m...)
 }
 
 // PUT registers a new PUT route for a path with matching handler
-func (e *Enlight) PUT(path string, handle HandleFunc, m ...MiddlewareFunc) {
-	e.Add(fasthttp.MethodPut, path, handle, m...)
+func (e *Enlight) PUT(path string, handle HandleFunc, m ...MiddlewareFunc) *Route {
+	return e.Add(fasthttp.MethodPut, path, handle, m...)
 }
 
 // TRACE registers a new TRACE route for a path with matching handler
-func (e *Enlight) TRACE(path string, handle HandleFunc, m ...MiddlewareFunc) {
-	e.Add(fasthttp.MethodTrace, path, handle, m...)
+func (e *Enlight) TRACE(path string, handle HandleFunc, m ...MiddlewareFunc) *Route {
+	return e.Add(fasthttp.MethodTrace, path, handle, m...)
 }
 
 var (
@@ -140,17 +187,21 @@ var (
 )
 
 // Any registers a new route for all HTTP methods and path with matching handler
-func (e *Enlight) Any(path string, handle HandleFunc, middleware ...MiddlewareFunc) {
-	for _, m := range methods {
-		e.Add(m, path, handle, middleware...)
+func (e *Enlight) Any(path string, handle HandleFunc, middleware ...MiddlewareFunc) []*Route {
+	routes := make([]*Route, len(methods))
+	for i, m := range methods {
+		routes[i] = e.Add(m, path, handle, middleware...)
 	}
+	return routes
 }
 
 // Match registers a new route for all given HTTP methods and path with matching handler
-func (e *Enlight) Match(methods []string, path string, handle HandleFunc, middleware ...MiddlewareFunc) {
-	for _, m := range methods {
-		e.Add(m, path, handle, middleware...)
+func (e *Enlight) Match(methods []string, path string, handle HandleFunc, middleware ...MiddlewareFunc) []*Route {
+	routes := make([]*Route, len(methods))
+	for i, m := range methods {
+		routes[i] = e.Add(m, path, handle, middleware...)
 	}
+	return routes
 }
 
 // Drop removes a route from router-tree
@@ -158,21 +209,32 @@ func (e *Enlight) Drop(method, path string) {
 	e.Router.Drop(method, path)
 }
 
+// URL generates a URL from the route registered under name, filling its
+// parameters with params.
+func (e *Enlight) URL(name string, params Params) (string, error) {
+	return e.Router.URL(name, params)
+}
+
 // Static serves static files
-func (e *Enlight) Static(prefix, root string) {
+func (e *Enlight) Static(prefix, root string) *Route {
 	if root == "" {
 		root = "."
 	}
-	e.static(prefix, root, e.GET)
+	return e.static(prefix, root, e.GET)
 }
 
 // Add registers a new route for an HTTP method and path with matching handler
 // in the router with optional route-level middleware.
-func (e *Enlight) Add(method, path string, handle HandleFunc, middleware ...MiddlewareFunc) {
-	e.add(method, path, handle, middleware...)
+func (e *Enlight) Add(method, path string, handle HandleFunc, middleware ...MiddlewareFunc) *Route {
+	return e.add(method, path, handle, middleware...)
 }
-func (e *Enlight) add(method, path string, handle HandleFunc, middleware ...MiddlewareFunc) {
-	e.Router.Handle(method, path, func(c Context) error {
+func (e *Enlight) add(method, path string, handle HandleFunc, middleware ...MiddlewareFunc) *Route {
+	var route *Route
+	route = e.Router.Handle(method, path, func(c Context) error {
+		c.(*context).path = path
+		if route.timeout > 0 {
+			c.(*context).setTimeout(route.timeout)
+		}
 		h := handle
 		// Chain middleware
 		for i := len(middleware) - 1; i >= 0; i-- {
@@ -180,9 +242,32 @@ func (e *Enlight) add(method, path string, handle HandleFunc, middleware ...Midd
 		}
 		return h(c)
 	}, false)
+	route.handler = handlerName(handle)
+	route.middleware = len(middleware)
+	return route
 }
 
-func (common) static(prefix, root string, get func(string, HandleFunc, ...MiddlewareFunc)) {
+// Routes returns the registered routes.
+func (e *Enlight) Routes() []RouteInfo {
+	return e.Router.Routes()
+}
+
+// IsTrustedProxy reports if ip is in one of the TrustedProxies, whose
+// forwarding headers like X-Forwarded-Proto can be believed.
+func (e *Enlight) IsTrustedProxy(ip net.IP) bool {
+	for _, network := range e.TrustedProxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func handlerName(h interface{}) string {
+	return runtime.FuncForPC(reflect.ValueOf(h).Pointer()).Name()
+}
+
+func (common) static(prefix, root string, get func(string, HandleFunc, ...MiddlewareFunc) *Route) *Route {
 	h := func(c Context) error {
 
 		p, err := url.PathUnescape(c.Param("filepath"))
@@ -193,11 +278,9 @@ func (common) static(prefix, root string, get func(string, HandleFunc, ...Middle
 		return c.File(name)
 	}
 	if prefix == "/" {
-		get(prefix+"*filepath", h)
-		return
+		return get(prefix+"*filepath", h)
 	}
-	get(prefix+"/*filepath", h)
-	return
+	return get(prefix+"/*filepath", h)
 }
 
 // ServeHTTP implements `http.Handler` interface, which serves HTTP requests.
@@ -224,7 +307,6 @@ func (e *Enlight) ServeHTTP(ctx *fasthttp.RequestCtx) {
 	}
 
 	if err := h(c); err != nil {
-		fmt.Println(err)
 		e.HTTPErrorHandler(err, c)
 	}
 
@@ -240,12 +322,12 @@ func (e *Enlight) ServeHTTP(ctx *fasthttp.RequestCtx) {
 		after = applyMiddleware(after, e.aftermiddleware...)
 
 		if err := after(c); err != nil {
-			fmt.Println(err)
 			e.HTTPErrorHandler(err, c)
 		}
 	}
 
 	// Clearing ref to fasthttp
+	c.release()
 	c.RequestCtx = nil
 	e.pool.Put(c)
 }
@@ -257,17 +339,298 @@ func (e *Enlight) Start(address string) error {
 
 // StartServer starts a custom http server.
 func (e *Enlight) StartServer(address string) (err error) {
-	e.Server = &fasthttp.Server{
-		Name:    "Enlight",
-		Handler: e.ServeHTTP,
+	ln, err := net.Listen("tcp4", address)
+	if err != nil {
+		return err
 	}
+	return e.Serve(ln)
+}
+
+// Serve serves HTTP requests on ln, e.g. a Unix domain socket, a listener
+// inherited from systemd or an in-memory listener in tests.
+func (e *Enlight) Serve(ln net.Listener) error {
+	return e.serve(e.Server, ln, "http")
+}
 
-	fmt.Printf("⇨ http server started on %s\n", address)
-	return e.Server.ListenAndServe(address)
+func (e *Enlight) serve(s *fasthttp.Server, ln net.Listener, scheme string) error {
+	if s.Handler == nil {
+		s.Handler = e.ServeHTTP
+	}
+	e.renewRequestContext()
+
+	if !e.HideBanner {
+		e.Logger.Info(scheme+" server started", "address", ln.Addr().String())
+	}
+	return s.Serve(ln)
 }
 
-// Shutdown stops the server gracefully.
+// StartTLS starts an HTTPS server with the certificate and key files. Further
+// certificates can be added with `Enlight#AddCertificate()`, the certificate
+// is selected by the server name the client requests (SNI).
+func (e *Enlight) StartTLS(address, certFile, keyFile string) error {
+	if certFile != "" || keyFile != "" {
+		if err := e.AddCertificateFile(certFile, keyFile); err != nil {
+			return err
+		}
+	}
+	return e.StartTLSWithConfig(address, &tls.Config{MinVersion: tls.VersionTLS12})
+}
+
+// StartTLSWithConfig starts an HTTPS server on the TLSServer with config.
+// The certificates added with `Enlight#AddCertificate()` are appended to
+// the certificates of config. A nil config only allows TLS 1.2 and newer.
+func (e *Enlight) StartTLSWithConfig(address string, config *tls.Config) error {
+	if config == nil {
+		config = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	config = config.Clone()
+	config.Certificates = append(config.Certificates, e.certificates...)
+	if len(config.Certificates) == 0 && config.GetCertificate == nil && config.GetConfigForClient == nil {
+		return ErrCertificateMissing
+	}
+
+	ln, err := net.Listen("tcp4", address)
+	if err != nil {
+		return err
+	}
+	return e.serve(e.TLSServer, tls.NewListener(ln, config), "https")
+}
+
+// AddCertificate adds a PEM encoded certificate and key to be served by
+// the HTTPS server.
+func (e *Enlight) AddCertificate(certPEM, keyPEM []byte) error {
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return err
+	}
+	e.certificates = append(e.certificates, cert)
+	return nil
+}
+
+// AddCertificateFile adds a certificate and key from PEM encoded files to
+// be served by the HTTPS server.
+func (e *Enlight) AddCertificateFile(certFile, keyFile string) error {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return err
+	}
+	e.certificates = append(e.certificates, cert)
+	return nil
+}
+
+// StartRedirectHTTPS starts the RedirectServer, which permanently redirects
+// all requests to HTTPS, used next to `Enlight#StartTLS()`. The port of the
+// HTTPS server is added to the redirect unless it is empty or "443".
+func (e *Enlight) StartRedirectHTTPS(address, httpsPort string) error {
+	ln, err := net.Listen("tcp4", address)
+	if err != nil {
+		return err
+	}
+	e.RedirectServer.Handler = redirectHTTPS(httpsPort)
+	return e.serve(e.RedirectServer, ln, "https redirect")
+}
+
+// redirectHTTPS returns a handler redirecting requests to the same URL
+// using HTTPS on port.
+func redirectHTTPS(port string) fasthttp.RequestHandler {
+	return func(ctx *fasthttp.RequestCtx) {
+		host := string(ctx.Host())
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		if port != "" && port != "443" {
+			host = net.JoinHostPort(host, port)
+		}
+		ctx.Response.Header.Set(HeaderLocation, "https://"+host+string(ctx.RequestURI()))
+		ctx.SetStatusCode(fasthttp.StatusMovedPermanently)
+	}
+}
+
+// StartWithContext starts an HTTP server and runs it until ctx is done or
+// the process receives SIGINT or SIGTERM. The server is then shut down
+// gracefully, see `Enlight#ShutdownWithContext()`. The OnStart hooks run
+// before the server starts listening.
+func (e *Enlight) StartWithContext(ctx gocontext.Context, address string) error {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	if err := runHooks(ctx, e.onStart); err != nil {
+		return err
+	}
+
+	ln, err := net.Listen("tcp4", address)
+	if err != nil {
+		return err
+	}
+	return e.serveUntilDone(ctx, ln, signals)
+}
+
+// ServeWithContext implements `Enlight#StartWithContext()` for serving on ln.
+func (e *Enlight) ServeWithContext(ctx gocontext.Context, ln net.Listener) error {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	if err := runHooks(ctx, e.onStart); err != nil {
+		ln.Close()
+		return err
+	}
+	return e.serveUntilDone(ctx, ln, signals)
+}
+
+// serveUntilDone serves on ln until ctx is done or a signal is received,
+// then shuts the server down gracefully.
+func (e *Enlight) serveUntilDone(ctx gocontext.Context, ln net.Listener, signals chan os.Signal) error {
+	errc := make(chan error, 1)
+	go func() {
+		errc <- e.Serve(ln)
+	}()
+
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	case <-signals:
+	}
+
+	shutdownCtx, cancel := gocontext.WithTimeout(gocontext.Background(), e.ShutdownTimeout)
+	defer cancel()
+	err := e.ShutdownWithContext(shutdownCtx)
+	// The server may not have picked up the listener yet
+	ln.Close()
+	if serr := <-errc; err == nil {
+		err = serr
+	}
+	return err
+}
+
+// PrintRoutes writes the registered routes as JSON to w. Applications call
+// it when run with the "routes" argument by the `enlight routes` command.
+func (e *Enlight) PrintRoutes(w io.Writer) error {
+	return json.NewEncoder(w).Encode(e.Routes())
+}
+
+// Shutdown stops the server gracefully, waiting at most ShutdownTimeout
+// for in-flight requests.
 func (e *Enlight) Shutdown() error {
-	fmt.Print("⇨ Server is shutting down...")
-	return e.Server.Shutdown()
+	ctx, cancel := gocontext.WithTimeout(gocontext.Background(), e.ShutdownTimeout)
+	defer cancel()
+	return e.ShutdownWithContext(ctx)
+}
+
+// ShutdownWithContext stops the server gracefully. It runs the OnShutdown
+// hooks, cancels the Go contexts of in-flight requests so they can wrap up,
+// stops accepting connections and waits for the requests until ctx is done,
+// then runs the OnShutdownComplete hooks. Hooks run in the order they were
+// registered.
+//
+// If ctx is done before the requests finished, ctx.Err() is returned
+// without running the OnShutdownComplete hooks, as the requests may still
+// use the resources the hooks release.
+func (e *Enlight) ShutdownWithContext(ctx gocontext.Context) error {
+	if !e.HideBanner {
+		e.Logger.Info("server is shutting down")
+	}
+
+	err := runHooks(ctx, e.onShutdown)
+	// Ask in-flight requests to give up, their responses are still sent
+	e.cancelRequestContext()
+
+	servers := []*fasthttp.Server{e.Server, e.TLSServer, e.RedirectServer}
+	drained := make(chan error, 1)
+	go func() {
+		errs := make(chan error, len(servers))
+		for _, s := range servers {
+			go func(s *fasthttp.Server) {
+				errs <- s.Shutdown()
+			}(s)
+		}
+		var serr error
+		for range servers {
+			if err := <-errs; serr == nil {
+				serr = err
+			}
+		}
+		drained <- serr
+	}()
+	select {
+	case serr := <-drained:
+		if err == nil {
+			err = serr
+		}
+	case <-ctx.Done():
+		if err == nil {
+			err = ctx.Err()
+		}
+		e.Logger.Warn("in-flight requests did not finish, skipping shutdown complete hooks", "error", err)
+		return err
+	}
+
+	// ctx may be nearly expired after draining
+	hookCtx, cancel := gocontext.WithTimeout(gocontext.Background(), e.ShutdownTimeout)
+	defer cancel()
+	if herr := runHooks(hookCtx, e.onShutdownComplete); err == nil {
+		err = herr
+	}
+	return err
+}
+
+// requestContext returns the parent of the Go contexts of requests.
+func (e *Enlight) requestContext() gocontext.Context {
+	e.requestMutex.RLock()
+	defer e.requestMutex.RUnlock()
+	if e.requestCtx == nil {
+		return gocontext.Background()
+	}
+	return e.requestCtx
+}
+
+// renewRequestContext creates the parent of the Go contexts of requests,
+// unless a running server uses it.
+func (e *Enlight) renewRequestContext() {
+	e.requestMutex.Lock()
+	defer e.requestMutex.Unlock()
+	if e.requestCtx == nil || e.requestCtx.Err() != nil {
+		e.requestCtx, e.cancelRequests = gocontext.WithCancel(gocontext.Background())
+	}
+}
+
+// cancelRequestContext cancels the Go contexts of in-flight requests.
+func (e *Enlight) cancelRequestContext() {
+	e.requestMutex.RLock()
+	defer e.requestMutex.RUnlock()
+	if e.cancelRequests != nil {
+		e.cancelRequests()
+	}
+}
+
+// OnStart registers a hook which runs before the server starts listening
+// in `Enlight#StartWithContext()`. An error aborts the start.
+func (e *Enlight) OnStart(hook Hook) {
+	e.onStart = append(e.onStart, hook)
+}
+
+// OnShutdown registers a hook which runs when the shutdown begins, before
+// in-flight requests are drained.
+func (e *Enlight) OnShutdown(hook Hook) {
+	e.onShutdown = append(e.onShutdown, hook)
+}
+
+// OnShutdownComplete registers a hook which runs after in-flight requests
+// have been drained, e.g. to close database connections. Hooks get a new
+// context limited by ShutdownTimeout and are skipped if the requests were
+// not drained in time.
+func (e *Enlight) OnShutdownComplete(hook Hook) {
+	e.onShutdownComplete = append(e.onShutdownComplete, hook)
+}
+
+// runHooks runs all hooks, returning the first error.
+func runHooks(ctx gocontext.Context, hooks []Hook) (err error) {
+	for _, hook := range hooks {
+		if herr := hook(ctx); herr != nil && err == nil {
+			err = herr
+		}
+	}
+	return
 }
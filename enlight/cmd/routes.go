@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"text/tabwriter"
+	"time"
+
+	"github.com/juliankoehn/enlight"
+	"github.com/spf13/cobra"
+)
+
+const routesExample = `$ enlight routes
+$ enlight routes ./cmd/server --json`
+
+var routesOptions = struct {
+	JSON    bool
+	Timeout time.Duration
+}{}
+
+// routesCmd builds and runs the application and prints its registered routes.
+var routesCmd = &cobra.Command{
+	Use:     "routes [package]",
+	Example: routesExample,
+	Short:   "Print all routes registered by an Enlight application",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		pkg := "."
+		if len(args) > 0 {
+			pkg = args[0]
+		}
+
+		routes, err := loadRoutes(pkg, routesOptions.Timeout)
+		if err != nil {
+			return err
+		}
+
+		out := cmd.OutOrStdout()
+		if routesOptions.JSON {
+			enc := json.NewEncoder(out)
+			enc.SetIndent("", "  ")
+			return enc.Encode(routes)
+		}
+
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "METHOD\tPATH\tNAME\tHANDLER\tMIDDLEWARE")
+		fmt.Fprintln(w, "------\t----\t----\t-------\t----------")
+		for _, r := range routes {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\n", r.Method, r.Path, r.Name, r.Handler, r.Middleware)
+		}
+		return w.Flush()
+	},
+}
+
+// loadRoutes builds the application in pkg and runs it with the "routes"
+// argument, on which it prints its routes with `Enlight#PrintRoutes()`
+// instead of starting the server. An application still running after the
+// timeout is killed.
+func loadRoutes(pkg string, timeout time.Duration) ([]enlight.RouteInfo, error) {
+	dir, err := ioutil.TempDir("", "enlight-routes")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	// The binary is run directly, killing `go run` would leave it running
+	bin := filepath.Join(dir, "app")
+	if runtime.GOOS == "windows" {
+		bin += ".exe"
+	}
+	build := exec.Command("go", "build", "-o", bin, pkg)
+	build.Stdout = os.Stderr
+	build.Stderr = os.Stderr
+	if err := build.Run(); err != nil {
+		return nil, fmt.Errorf("could not build %s: %v", pkg, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	run := exec.CommandContext(ctx, bin, "routes")
+	run.Stderr = os.Stderr
+
+	out, err := run.Output()
+	if ctx.Err() == context.DeadlineExceeded {
+		return nil, fmt.Errorf("%s did not print its routes within %s, does it call PrintRoutes?", pkg, timeout)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not run %s: %v", pkg, err)
+	}
+
+	// The routes are the last line written, anything before it was
+	// written by the application itself.
+	out = bytes.TrimSpace(out)
+	if i := bytes.LastIndexByte(out, '\n'); i >= 0 {
+		out = out[i+1:]
+	}
+
+	var routes []enlight.RouteInfo
+	if err := json.Unmarshal(out, &routes); err != nil {
+		return nil, fmt.Errorf("%s did not print its routes, does it call PrintRoutes? %v", pkg, err)
+	}
+	return routes, nil
+}
+
+func init() {
+	routesCmd.Flags().BoolVar(&routesOptions.JSON, "json", false, "print the routes as JSON")
+	routesCmd.Flags().DurationVar(&routesOptions.Timeout, "timeout", 10*time.Second, "time the application has to print its routes")
+	RootCmd.AddCommand(routesCmd)
+}
@@ -2,10 +2,13 @@ package enlight
 
 import (
 	ctx "context"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net"
 	"net/http"
+	"sync"
+	"syscall"
 	"testing"
 	"time"
 
@@ -56,6 +59,149 @@ func TestEnlightStart(t *testing.T) {
 	time.Sleep(200 * time.Millisecond)
 }
 
+func freeAddress(t *testing.T) string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	return ln.Addr().String()
+}
+
+func TestEnlightStartWithContext(t *testing.T) {
+	e := New()
+	e.GET("/slow", func(c Context) error {
+		time.Sleep(300 * time.Millisecond)
+		return c.String(http.StatusOK, "done")
+	})
+
+	var mutex sync.Mutex
+	stages := []string{}
+	hook := func(stage string) Hook {
+		return func(ctx.Context) error {
+			mutex.Lock()
+			stages = append(stages, stage)
+			mutex.Unlock()
+			return nil
+		}
+	}
+	e.OnStart(hook("start"))
+	e.OnShutdown(hook("shutdown"))
+	e.OnShutdownComplete(hook("complete"))
+
+	address := freeAddress(t)
+	c, cancel := ctx.WithCancel(ctx.Background())
+	stopped := make(chan error, 1)
+	go func() {
+		stopped <- e.StartWithContext(c, address)
+	}()
+	time.Sleep(100 * time.Millisecond)
+
+	// In-flight requests are drained
+	responses := make(chan string, 1)
+	go func() {
+		res, err := http.Get("http://" + address + "/slow")
+		if err != nil {
+			responses <- err.Error()
+			return
+		}
+		defer res.Body.Close()
+		body, _ := ioutil.ReadAll(res.Body)
+		responses <- string(body)
+	}()
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+
+	assert.Equal(t, "done", <-responses)
+	assert.NoError(t, <-stopped)
+	assert.Equal(t, []string{"start", "shutdown", "complete"}, stages)
+}
+
+func TestEnlightStartWithContextSignal(t *testing.T) {
+	e := New()
+	e.OnStart(func(ctx.Context) error {
+		go syscall.Kill(syscall.Getpid(), syscall.SIGTERM)
+		return nil
+	})
+	completed := false
+	e.OnShutdownComplete(func(ctx.Context) error {
+		completed = true
+		return nil
+	})
+
+	assert.NoError(t, e.StartWithContext(ctx.Background(), freeAddress(t)))
+	assert.True(t, completed)
+}
+
+func TestEnlightShutdownTimeout(t *testing.T) {
+	e := New()
+	e.ShutdownTimeout = 100 * time.Millisecond
+	canceled := make(chan error, 1)
+	e.GET("/hang", func(c Context) error {
+		select {
+		case <-c.Ctx().Done():
+			canceled <- c.Ctx().Err()
+		case <-time.After(time.Second):
+			canceled <- nil
+		}
+		// Keep running past the shutdown timeout
+		time.Sleep(300 * time.Millisecond)
+		return nil
+	})
+
+	address := freeAddress(t)
+	go e.Start(address)
+	time.Sleep(100 * time.Millisecond)
+	go http.Get("http://" + address + "/hang")
+	time.Sleep(100 * time.Millisecond)
+
+	completed := false
+	e.OnShutdownComplete(func(ctx.Context) error {
+		completed = true
+		return nil
+	})
+
+	shutdown := make(chan error, 1)
+	go func() {
+		shutdown <- e.Shutdown()
+	}()
+	// The request is canceled once the shutdown begins
+	select {
+	case err := <-canceled:
+		assert.Equal(t, ctx.Canceled, err)
+	case <-time.After(50 * time.Millisecond):
+		t.Error("request not canceled on shutdown")
+	}
+	assert.Equal(t, ctx.DeadlineExceeded, <-shutdown)
+	// Resources may still be in use by the canceled request
+	assert.False(t, completed)
+
+	// Requests of a restarted server are not canceled
+	e.Server = &fasthttp.Server{Handler: e.ServeHTTP}
+	e.GET("/ctx", func(c Context) error {
+		if err := c.Ctx().Err(); err != nil {
+			return c.String(500, err.Error())
+		}
+		return c.String(200, "ok")
+	})
+	address = freeAddress(t)
+	go e.Start(address)
+	time.Sleep(100 * time.Millisecond)
+	res, err := http.Get("http://" + address + "/ctx")
+	if assert.NoError(t, err) {
+		assert.Equal(t, 200, res.StatusCode)
+		res.Body.Close()
+	}
+}
+
+func TestEnlightOnStartError(t *testing.T) {
+	e := New()
+	e.OnStart(func(ctx.Context) error {
+		return errors.New("migrations failed")
+	})
+	assert.EqualError(t, e.StartWithContext(ctx.Background(), freeAddress(t)), "migrations failed")
+}
+
 func TestEnlightHandler(t *testing.T) {
 	e := New()
 
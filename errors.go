@@ -3,14 +3,25 @@ package enlight
 import (
 	"errors"
 	"fmt"
+	"reflect"
 
 	"github.com/valyala/fasthttp"
 )
 
 // Errors
 var (
-	ErrNotFound            = NewHTTPError(fasthttp.StatusNotFound)
-	ErrInvalidRedirectCode = errors.New("invalid redirect status code")
+	ErrNotFound               = NewHTTPError(fasthttp.StatusNotFound)
+	ErrMethodNotAllowed       = NewHTTPError(fasthttp.StatusMethodNotAllowed)
+	ErrUnsupportedMediaType   = NewHTTPError(fasthttp.StatusUnsupportedMediaType)
+	ErrNotAcceptable          = NewHTTPError(fasthttp.StatusNotAcceptable)
+	ErrServiceUnavailable     = NewHTTPError(fasthttp.StatusServiceUnavailable)
+	ErrInvalidJSONPCallback   = NewHTTPError(fasthttp.StatusBadRequest, "invalid JSONP callback")
+	ErrInvalidRedirectCode    = errors.New("invalid redirect status code")
+	ErrUnknownRoute           = errors.New("no route registered with that name")
+	ErrValidatorNotRegistered = errors.New("validator not registered")
+	ErrRendererNotRegistered  = errors.New("renderer not registered")
+	ErrStreamClosed           = errors.New("stream closed")
+	ErrCertificateMissing     = errors.New("no TLS certificate configured")
 )
 
 // HTTPError represents an error that occured while handling a request.
@@ -66,11 +77,24 @@ func (e *Enlight) DefaultHTTPErrorHandler(err error, c Context) {
 		}
 	}
 
+	if he.Code >= fasthttp.StatusInternalServerError {
+		c.Logger().Error("request failed", "status", he.Code, "error", err)
+	} else {
+		c.Logger().Debug("request failed", "status", he.Code, "error", err)
+	}
+
 	code := he.Code
 	message := he.Message
 
+	id := c.RequestID()
 	if m, ok := message.(string); ok {
-		message = Map{"message": m}
+		if id != "" {
+			message = Map{"message": m, "request_id": id}
+		} else {
+			message = Map{"message": m}
+		}
+	} else if id != "" {
+		message = withRequestID(message, id)
 	}
 
 	if string(c.Request().Method()) == fasthttp.MethodHead {
@@ -79,8 +103,23 @@ func (e *Enlight) DefaultHTTPErrorHandler(err error, c Context) {
 		err = c.JSON(code, message)
 	}
 	if err != nil {
-		fmt.Println(err)
-		// TODO: log the error
+		c.Logger().Error("failed to send error response", "error", err)
 	}
+}
 
+// withRequestID adds the request ID to a message which is not a string.
+// The entries of a map, e.g. ValidationErrors, are kept at the top level,
+// other messages are sent as "message".
+func withRequestID(message interface{}, id string) interface{} {
+	val := reflect.ValueOf(message)
+	if val.Kind() == reflect.Map && val.Type().Key().Kind() == reflect.String {
+		body := make(Map, val.Len()+1)
+		iter := val.MapRange()
+		for iter.Next() {
+			body[iter.Key().String()] = iter.Value().Interface()
+		}
+		body["request_id"] = id
+		return body
+	}
+	return Map{"message": message, "request_id": id}
 }
@@ -1,7 +1,9 @@
 package main
 
 import (
+	"context"
 	"fmt"
+	"os"
 	"strconv"
 	"sync"
 	"time"
@@ -163,7 +165,12 @@ func serve() (err error) {
 
 	e.After(app.CleanupDynamicRoutes)
 
-	if err = e.Start(":8085"); err != nil {
+	// Used by `enlight routes`
+	if len(os.Args) > 1 && os.Args[1] == "routes" {
+		return e.PrintRoutes(os.Stdout)
+	}
+
+	if err = e.StartWithContext(context.Background(), ":8085"); err != nil {
 		fmt.Printf("listen:%+s\n", err)
 		return err
 	}
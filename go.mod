@@ -3,15 +3,18 @@ module github.com/juliankoehn/enlight
 go 1.13
 
 require (
+	github.com/fasthttp/websocket v1.4.2
 	github.com/go-sql-driver/mysql v1.5.0
 	github.com/gobuffalo/buffalo v0.16.5 // indirect
 	github.com/gobuffalo/clara v0.10.1
 	github.com/jmoiron/sqlx v1.2.0
-	github.com/json-iterator/go v1.1.9
+	github.com/json-iterator/go v1.1.12
 	github.com/klauspost/compress v1.10.4 // indirect
 	github.com/modern-go/concurrent v0.0.0-20180306012644-bacd9c7ef1dd // indirect
-	github.com/modern-go/reflect2 v1.0.1 // indirect
+	github.com/modern-go/reflect2 v1.0.2 // indirect
 	github.com/spf13/cobra v1.0.0
 	github.com/stretchr/testify v1.5.1
 	github.com/valyala/fasthttp v1.11.0
+	github.com/vmihailenco/msgpack/v4 v4.3.12
+	gopkg.in/yaml.v2 v2.2.8
 )
@@ -0,0 +1,118 @@
+package enlight
+
+import (
+	"github.com/valyala/fasthttp"
+)
+
+// Group is a set of sub-routes for a specified route. It can be used for inner
+// routes that share a common middleware or functionality that should be separate
+// from the parent enlight instance while still inheriting from it.
+type Group struct {
+	common
+	prefix     string
+	middleware []MiddlewareFunc
+	enlight    *Enlight
+}
+
+// Group creates a new router group with prefix and optional group-level middleware.
+func (e *Enlight) Group(prefix string, m ...MiddlewareFunc) (g *Group) {
+	g = &Group{prefix: prefix, enlight: e}
+	g.Use(m...)
+	return
+}
+
+// Use implements `Enlight#Use()` for sub-routes within the Group.
+// Middleware is applied to routes registered after the call.
+func (g *Group) Use(middleware ...MiddlewareFunc) {
+	g.middleware = append(g.middleware, middleware...)
+}
+
+// CONNECT implements `Enlight#CONNECT()` for sub-routes within the Group.
+func (g *Group) CONNECT(path string, handle HandleFunc, m ...MiddlewareFunc) *Route {
+	return g.Add(fasthttp.MethodConnect, path, handle, m...)
+}
+
+// DELETE implements `Enlight#DELETE()` for sub-routes within the Group.
+func (g *Group) DELETE(path string, handle HandleFunc, m ...MiddlewareFunc) *Route {
+	return g.Add(fasthttp.MethodDelete, path, handle, m...)
+}
+
+// GET implements `Enlight#GET()` for sub-routes within the Group.
+func (g *Group) GET(path string, handle HandleFunc, m ...MiddlewareFunc) *Route {
+	return g.Add(fasthttp.MethodGet, path, handle, m...)
+}
+
+// HEAD implements `Enlight#HEAD()` for sub-routes within the Group.
+func (g *Group) HEAD(path string, handle HandleFunc, m ...MiddlewareFunc) *Route {
+	return g.Add(fasthttp.MethodHead, path, handle, m...)
+}
+
+// OPTIONS implements `Enlight#OPTIONS()` for sub-routes within the Group.
+func (g *Group) OPTIONS(path string, handle HandleFunc, m ...MiddlewareFunc) *Route {
+	return g.Add(fasthttp.MethodOptions, path, handle, m...)
+}
+
+// PATCH implements `Enlight#PATCH()` for sub-routes within the Group.
+func (g *Group) PATCH(path string, handle HandleFunc, m ...MiddlewareFunc) *Route {
+	return g.Add(fasthttp.MethodPatch, path, handle, m...)
+}
+
+// POST implements `Enlight#POST()` for sub-routes within the Group.
+func (g *Group) POST(path string, handle HandleFunc, m ...MiddlewareFunc) *Route {
+	return g.Add(fasthttp.MethodPost, path, handle, m...)
+}
+
+// PUT implements `Enlight#PUT()` for sub-routes within the Group.
+func (g *Group) PUT(path string, handle HandleFunc, m ...MiddlewareFunc) *Route {
+	return g.Add(fasthttp.MethodPut, path, handle, m...)
+}
+
+// TRACE implements `Enlight#TRACE()` for sub-routes within the Group.
+func (g *Group) TRACE(path string, handle HandleFunc, m ...MiddlewareFunc) *Route {
+	return g.Add(fasthttp.MethodTrace, path, handle, m...)
+}
+
+// Any implements `Enlight#Any()` for sub-routes within the Group.
+func (g *Group) Any(path string, handle HandleFunc, middleware ...MiddlewareFunc) []*Route {
+	routes := make([]*Route, len(methods))
+	for i, m := range methods {
+		routes[i] = g.Add(m, path, handle, middleware...)
+	}
+	return routes
+}
+
+// Match implements `Enlight#Match()` for sub-routes within the Group.
+func (g *Group) Match(methods []string, path string, handle HandleFunc, middleware ...MiddlewareFunc) []*Route {
+	routes := make([]*Route, len(methods))
+	for i, m := range methods {
+		routes[i] = g.Add(m, path, handle, middleware...)
+	}
+	return routes
+}
+
+// Group creates a new sub-group with prefix and optional sub-group-level middleware.
+func (g *Group) Group(prefix string, middleware ...MiddlewareFunc) *Group {
+	m := make([]MiddlewareFunc, 0, len(g.middleware)+len(middleware))
+	m = append(m, g.middleware...)
+	m = append(m, middleware...)
+	return g.enlight.Group(g.prefix+prefix, m...)
+}
+
+// Static implements `Enlight#Static()` for sub-routes within the Group.
+func (g *Group) Static(prefix, root string) *Route {
+	if root == "" {
+		root = "."
+	}
+	return g.static(prefix, root, g.GET)
+}
+
+// Add implements `Enlight#Add()` for sub-routes within the Group.
+func (g *Group) Add(method, path string, handle HandleFunc, middleware ...MiddlewareFunc) *Route {
+	// Combine into a new slice to avoid accidentally passing the same slice for
+	// multiple routes, which would lead to later add() calls overwriting the
+	// middleware from earlier calls.
+	m := make([]MiddlewareFunc, 0, len(g.middleware)+len(middleware))
+	m = append(m, g.middleware...)
+	m = append(m, middleware...)
+	return g.enlight.Add(method, g.prefix+path, handle, m...)
+}
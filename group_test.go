@@ -0,0 +1,48 @@
+package enlight
+
+import (
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroupRoutes(t *testing.T) {
+	e := New()
+
+	header := func(key, value string) MiddlewareFunc {
+		return func(next HandleFunc) HandleFunc {
+			return func(c Context) error {
+				c.Response().Header.Add(key, value)
+				return next(c)
+			}
+		}
+	}
+
+	api := e.Group("/api", header("X-Group", "api"))
+	v1 := api.Group("/v1", header("X-Group", "v1"))
+	v1.Use(header("X-Group", "use"))
+	v1.GET("/users/:id", func(c Context) error {
+		return c.String(200, "user "+c.Param("id"))
+	}, header("X-Group", "route"))
+	api.Match([]string{"PUT", "PATCH"}, "/status", func(c Context) error {
+		return c.String(200, "status")
+	})
+
+	r, _ := http.NewRequest("GET", "http://test/api/v1/users/42", nil)
+	res, err := serve(e.ServeHTTP, r)
+	if assert.NoError(t, err) {
+		body, _ := ioutil.ReadAll(res.Body)
+		assert.Equal(t, "user 42", string(body))
+		assert.Equal(t, []string{"api", "v1", "use", "route"}, res.Header["X-Group"])
+	}
+
+	r, _ = http.NewRequest("PATCH", "http://test/api/status", nil)
+	res, err = serve(e.ServeHTTP, r)
+	if assert.NoError(t, err) {
+		body, _ := ioutil.ReadAll(res.Body)
+		assert.Equal(t, "status", string(body))
+		assert.Equal(t, []string{"api"}, res.Header["X-Group"])
+	}
+}
@@ -0,0 +1,14 @@
+// Package contexts gives the middleware access to the unexported methods of
+// the enlight context, which are not part of the `enlight.Context` interface.
+package contexts
+
+var (
+	// Detach returns a copy of an `enlight.Context` with its own copy of
+	// the request and response. It is set by the enlight package.
+	Detach func(c interface{}) interface{}
+
+	// Merge copies the response, the route path and the stored values of
+	// a context returned by Detach back to the context. It is set by the
+	// enlight package.
+	Merge func(c, detached interface{})
+)
@@ -0,0 +1,149 @@
+//go:build !windows
+// +build !windows
+
+package enlight
+
+import (
+	ctx "context"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp/fasthttputil"
+)
+
+func helloEnlight() *Enlight {
+	e := New()
+	e.GET("/", func(c Context) error {
+		return c.String(http.StatusOK, "hello")
+	})
+	return e
+}
+
+func get(t *testing.T, dial func() (net.Conn, error)) string {
+	client := http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx.Context, string, string) (net.Conn, error) {
+				return dial()
+			},
+			DisableKeepAlives: true,
+		},
+	}
+	res, err := client.Get("http://test/")
+	if !assert.NoError(t, err) {
+		return ""
+	}
+	defer res.Body.Close()
+	body, _ := ioutil.ReadAll(res.Body)
+	return string(body)
+}
+
+func TestEnlightServe(t *testing.T) {
+	e := helloEnlight()
+	ln := fasthttputil.NewInmemoryListener()
+	go e.Serve(ln)
+
+	assert.Equal(t, "hello", get(t, ln.Dial))
+	assert.NoError(t, e.Shutdown())
+}
+
+func TestEnlightServeWithContext(t *testing.T) {
+	e := helloEnlight()
+	ln := fasthttputil.NewInmemoryListener()
+	c, cancel := ctx.WithCancel(ctx.Background())
+	stopped := make(chan error, 1)
+	go func() {
+		stopped <- e.ServeWithContext(c, ln)
+	}()
+
+	assert.Equal(t, "hello", get(t, ln.Dial))
+	cancel()
+	assert.NoError(t, <-stopped)
+}
+
+func TestListenUnix(t *testing.T) {
+	dir, err := ioutil.TempDir("", "enlight")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "enlight.sock")
+
+	// Other files are not removed
+	assert.NoError(t, ioutil.WriteFile(path, []byte("data"), 0600))
+	_, err = ListenUnix(path, 0660)
+	assert.EqualError(t, err, path+" exists and is not a socket")
+	b, _ := ioutil.ReadFile(path)
+	assert.Equal(t, "data", string(b))
+	assert.NoError(t, os.Remove(path))
+
+	// Stale socket files are replaced
+	stale, err := net.ListenUnix("unix", &net.UnixAddr{Name: path, Net: "unix"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale.SetUnlinkOnClose(false)
+	stale.Close()
+	ln, err := ListenUnix(path, 0660)
+	if !assert.NoError(t, err) {
+		return
+	}
+	info, err := os.Stat(path)
+	if assert.NoError(t, err) {
+		assert.Equal(t, os.FileMode(0660), info.Mode().Perm())
+	}
+
+	e := helloEnlight()
+	go e.Serve(ln)
+	assert.Equal(t, "hello", get(t, func() (net.Conn, error) {
+		return net.Dial("unix", path)
+	}))
+	assert.NoError(t, e.Shutdown())
+}
+
+func TestSystemdListeners(t *testing.T) {
+	// Not socket activated
+	listeners, err := SystemdListeners()
+	assert.NoError(t, err)
+	assert.Empty(t, listeners)
+
+	tcp, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	address := tcp.Addr().String()
+	f, err := tcp.(*net.TCPListener).File()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// listenFDs takes ownership of the descriptor
+	fd, err := syscall.Dup(int(f.Fd()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	tcp.Close()
+
+	os.Setenv("LISTEN_PID", strconv.Itoa(os.Getpid()))
+	os.Setenv("LISTEN_FDS", "1")
+	listeners, err = listenFDs(fd)
+	if !assert.NoError(t, err) || !assert.Len(t, listeners, 1) {
+		return
+	}
+	assert.Equal(t, "", os.Getenv("LISTEN_FDS"))
+
+	e := helloEnlight()
+	go e.Serve(listeners[0])
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, "hello", get(t, func() (net.Conn, error) {
+		return net.Dial("tcp", address)
+	}))
+	assert.NoError(t, e.Shutdown())
+}
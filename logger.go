@@ -0,0 +1,207 @@
+package enlight
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	json "github.com/json-iterator/go"
+)
+
+type (
+	// Logger is the interface of the Enlight logger. Messages are logged
+	// with structured fields given as alternating keys and values, e.g.
+	// `logger.Info("user created", "id", 42)`.
+	Logger interface {
+		Debug(msg string, fields ...interface{})
+		Info(msg string, fields ...interface{})
+		Warn(msg string, fields ...interface{})
+		Error(msg string, fields ...interface{})
+
+		// With returns a Logger which adds fields to every message.
+		With(fields ...interface{}) Logger
+	}
+
+	// Level is the severity of a log message.
+	Level uint8
+
+	// LogFormat is the output format of the StdLogger.
+	LogFormat uint8
+
+	// StdLogger is the default Logger. It writes one line per message as
+	// text or JSON.
+	StdLogger struct {
+		out    *logOutput
+		fields []interface{}
+	}
+
+	// logOutput is shared by a StdLogger and the loggers derived with With,
+	// so changing the level, format or writer applies to all of them.
+	logOutput struct {
+		mutex  sync.Mutex
+		w      io.Writer
+		level  Level
+		format LogFormat
+	}
+)
+
+// Levels
+const (
+	DEBUG Level = iota
+	INFO
+	WARN
+	ERROR
+	OFF
+)
+
+// Formats
+const (
+	LogFormatText LogFormat = iota
+	LogFormatJSON
+)
+
+var levelNames = [...]string{"debug", "info", "warn", "error", "off"}
+
+// NewLogger returns a StdLogger writing text with level INFO to out.
+func NewLogger(out io.Writer) *StdLogger {
+	return &StdLogger{
+		out: &logOutput{w: out, level: INFO},
+	}
+}
+
+// String returns the name of the level.
+func (l Level) String() string {
+	if int(l) < len(levelNames) {
+		return levelNames[l]
+	}
+	return "level(" + strconv.Itoa(int(l)) + ")"
+}
+
+// SetLevel sets the minimum level of messages written. It also applies to
+// the loggers derived with With.
+func (l *StdLogger) SetLevel(level Level) {
+	l.out.mutex.Lock()
+	l.out.level = level
+	l.out.mutex.Unlock()
+}
+
+// SetFormat sets the output format. It also applies to the loggers derived
+// with With.
+func (l *StdLogger) SetFormat(format LogFormat) {
+	l.out.mutex.Lock()
+	l.out.format = format
+	l.out.mutex.Unlock()
+}
+
+// SetOutput sets the writer messages are written to.
+func (l *StdLogger) SetOutput(w io.Writer) {
+	l.out.mutex.Lock()
+	l.out.w = w
+	l.out.mutex.Unlock()
+}
+
+// Debug logs a message with level DEBUG.
+func (l *StdLogger) Debug(msg string, fields ...interface{}) {
+	l.log(DEBUG, msg, fields)
+}
+
+// Info logs a message with level INFO.
+func (l *StdLogger) Info(msg string, fields ...interface{}) {
+	l.log(INFO, msg, fields)
+}
+
+// Warn logs a message with level WARN.
+func (l *StdLogger) Warn(msg string, fields ...interface{}) {
+	l.log(WARN, msg, fields)
+}
+
+// Error logs a message with level ERROR.
+func (l *StdLogger) Error(msg string, fields ...interface{}) {
+	l.log(ERROR, msg, fields)
+}
+
+// With implements the `Logger#With` function.
+func (l *StdLogger) With(fields ...interface{}) Logger {
+	c := *l
+	c.fields = make([]interface{}, 0, len(l.fields)+len(fields))
+	c.fields = append(c.fields, l.fields...)
+	c.fields = append(c.fields, fields...)
+	return &c
+}
+
+func (l *StdLogger) log(level Level, msg string, fields []interface{}) {
+	l.out.mutex.Lock()
+	minLevel, format := l.out.level, l.out.format
+	l.out.mutex.Unlock()
+	if level < minLevel {
+		return
+	}
+
+	buf := new(bytes.Buffer)
+	now := time.Now().Format(time.RFC3339)
+	if format == LogFormatJSON {
+		buf.WriteString(`{"time":"` + now + `","level":"` + level.String() + `","msg":`)
+		writeJSON(buf, msg)
+		appendFields(buf, l.fields, format)
+		appendFields(buf, fields, format)
+		buf.WriteString("}\n")
+	} else {
+		buf.WriteString(now + " " + strings.ToUpper(level.String()) + " " + msg)
+		appendFields(buf, l.fields, format)
+		appendFields(buf, fields, format)
+		buf.WriteByte('\n')
+	}
+
+	l.out.mutex.Lock()
+	l.out.w.Write(buf.Bytes())
+	l.out.mutex.Unlock()
+}
+
+// appendFields writes the key/value pairs of fields. A key without value
+// is logged with the key "!BADKEY".
+func appendFields(buf *bytes.Buffer, fields []interface{}, format LogFormat) {
+	for i := 0; i < len(fields); i += 2 {
+		var key string
+		var value interface{}
+		if i+1 < len(fields) {
+			key, value = fmt.Sprint(fields[i]), fields[i+1]
+		} else {
+			key, value = "!BADKEY", fields[i]
+		}
+		if err, ok := value.(error); ok {
+			value = err.Error()
+		}
+
+		if format == LogFormatJSON {
+			buf.WriteByte(',')
+			writeJSON(buf, key)
+			buf.WriteByte(':')
+			writeJSON(buf, value)
+			continue
+		}
+
+		buf.WriteByte(' ')
+		buf.WriteString(key)
+		buf.WriteByte('=')
+		s, ok := value.(string)
+		if !ok {
+			s = fmt.Sprint(value)
+		}
+		if s == "" || strings.ContainsAny(s, " \t\n\"=") {
+			s = strconv.Quote(s)
+		}
+		buf.WriteString(s)
+	}
+}
+
+func writeJSON(buf *bytes.Buffer, v interface{}) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		b, _ = json.Marshal(fmt.Sprint(v))
+	}
+	buf.Write(b)
+}
@@ -0,0 +1,98 @@
+package enlight
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	json "github.com/json-iterator/go"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStdLogger(t *testing.T) {
+	buf := new(bytes.Buffer)
+	l := NewLogger(buf)
+
+	l.Debug("hidden")
+	l.Info("user created", "id", 42, "name", "Jon Snow")
+	l.With("component", "mailer").Warn("retrying", "error", errors.New("timeout"), "attempt")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if assert.Len(t, lines, 2) {
+		assert.True(t, strings.HasSuffix(lines[0], ` INFO user created id=42 name="Jon Snow"`), lines[0])
+		assert.True(t, strings.HasSuffix(lines[1], ` WARN retrying component=mailer error=timeout !BADKEY=attempt`), lines[1])
+	}
+
+	buf.Reset()
+	l.SetLevel(DEBUG)
+	l.SetFormat(LogFormatJSON)
+	l.With("request", 1).Debug("query", "sql", `SELECT "id"`)
+
+	var entry map[string]interface{}
+	if assert.NoError(t, json.Unmarshal(buf.Bytes(), &entry)) {
+		assert.Equal(t, "debug", entry["level"])
+		assert.Equal(t, "query", entry["msg"])
+		assert.Equal(t, float64(1), entry["request"])
+		assert.Equal(t, `SELECT "id"`, entry["sql"])
+		assert.NotEmpty(t, entry["time"])
+	}
+
+	buf.Reset()
+	l.SetLevel(OFF)
+	l.Error("hidden")
+	assert.Empty(t, buf.String())
+}
+
+func TestStdLoggerConcurrent(t *testing.T) {
+	buf := new(bytes.Buffer)
+	l := NewLogger(buf)
+	derived := l.With("component", "mailer")
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			derived.Info("sending", "attempt", i)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			l.SetLevel(Level(i % 2 * int(WARN)))
+			l.SetFormat(LogFormat(i % 2))
+		}
+	}()
+	wg.Wait()
+
+	// Loggers derived with With follow the level of their parent
+	buf.Reset()
+	l.SetLevel(ERROR)
+	derived.Warn("hidden")
+	assert.Empty(t, buf.String())
+}
+
+func TestContextLogger(t *testing.T) {
+	buf := new(bytes.Buffer)
+	e := New()
+	e.Logger = NewLogger(buf)
+	e.GET("/users/:id", func(c Context) error {
+		c.Logger().Info("loading user")
+		return errors.New("database down")
+	})
+
+	req, _ := http.NewRequest("GET", "http://test/users/1", nil)
+	res, err := serve(e.ServeHTTP, req)
+	if assert.NoError(t, err) {
+		assert.Equal(t, http.StatusInternalServerError, res.StatusCode)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if assert.Len(t, lines, 2) {
+		assert.Contains(t, lines[0], "INFO loading user method=GET path=/users/1")
+		assert.Contains(t, lines[1], `ERROR request failed method=GET path=/users/1 status=500 error="database down"`)
+	}
+}
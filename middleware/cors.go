@@ -0,0 +1,211 @@
+package middleware
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/juliankoehn/enlight"
+	"github.com/valyala/fasthttp"
+)
+
+type (
+	// CORSConfig defines the config for CORS middleware.
+	CORSConfig struct {
+		// Skipper defines a function to skip middleware
+		Skipper Skipper
+
+		// AllowOrigins is the list of origins which may access the resource.
+		// An entry is either "*" for any origin, an exact origin like
+		// "https://example.com" or a wildcard subdomain like
+		// "https://*.example.com".
+		// Optional. Default value []string{"*"}.
+		AllowOrigins []string `yaml:"allow_origins"`
+
+		// AllowOriginPatterns is a list of regular expressions matched
+		// against the whole origin, in addition to AllowOrigins.
+		// Optional. Default value []string{}.
+		AllowOriginPatterns []string `yaml:"allow_origin_patterns"`
+
+		// AllowMethods is the list of methods allowed in preflight requests.
+		// Optional. Default value DefaultCORSConfig.AllowMethods.
+		AllowMethods []string `yaml:"allow_methods"`
+
+		// AllowHeaders is the list of request headers allowed in preflight
+		// requests.
+		// Optional. Default value are the headers requested by the client.
+		AllowHeaders []string `yaml:"allow_headers"`
+
+		// AllowCredentials allows requests with cookies, authorization headers
+		// or TLS client certificates. It cannot be combined with AllowOrigins
+		// "*", which would let any site make authenticated requests, the
+		// allowed origins have to be listed.
+		// Optional. Default value false.
+		AllowCredentials bool `yaml:"allow_credentials"`
+
+		// ExposeHeaders is the list of response headers clients may read.
+		// Optional. Default value []string{}.
+		ExposeHeaders []string `yaml:"expose_headers"`
+
+		// MaxAge is the time in seconds the result of a preflight request may
+		// be cached.
+		// Optional. Default value 0, which omits the header.
+		MaxAge int `yaml:"max_age"`
+	}
+)
+
+var (
+	// DefaultCORSConfig is the default CORS middleware config.
+	DefaultCORSConfig = CORSConfig{
+		Skipper:      DefaultSkipper,
+		AllowOrigins: []string{"*"},
+		AllowMethods: []string{
+			fasthttp.MethodGet,
+			fasthttp.MethodHead,
+			fasthttp.MethodPut,
+			fasthttp.MethodPatch,
+			fasthttp.MethodPost,
+			fasthttp.MethodDelete,
+		},
+	}
+)
+
+// CORS returns a Cross-Origin Resource Sharing middleware which allows
+// requests from any origin.
+//
+// Preflight requests are answered by the middleware, so it must be added
+// with `Enlight#Use()` and no OPTIONS routes are required.
+func CORS() enlight.MiddlewareFunc {
+	return CORSWithConfig(DefaultCORSConfig)
+}
+
+// CORSWithConfig returns a CORS middleware with config.
+// See: `CORS()`.
+func CORSWithConfig(config CORSConfig) enlight.MiddlewareFunc {
+	// Defaults
+	if config.Skipper == nil {
+		config.Skipper = DefaultCORSConfig.Skipper
+	}
+	if len(config.AllowOrigins) == 0 && len(config.AllowOriginPatterns) == 0 {
+		config.AllowOrigins = DefaultCORSConfig.AllowOrigins
+	}
+	if len(config.AllowMethods) == 0 {
+		config.AllowMethods = DefaultCORSConfig.AllowMethods
+	}
+
+	anyOrigin := false
+	for _, origin := range config.AllowOrigins {
+		if origin == "*" {
+			anyOrigin = true
+		}
+	}
+	if anyOrigin && config.AllowCredentials {
+		panic("cors: AllowOrigins \"*\" cannot be combined with AllowCredentials")
+	}
+	patterns := make([]*regexp.Regexp, len(config.AllowOriginPatterns))
+	for i, pattern := range config.AllowOriginPatterns {
+		patterns[i] = regexp.MustCompile("^(?:" + pattern + ")$")
+	}
+
+	allowMethods := strings.Join(config.AllowMethods, ",")
+	allowHeaders := strings.Join(config.AllowHeaders, ",")
+	exposeHeaders := strings.Join(config.ExposeHeaders, ",")
+	maxAge := strconv.Itoa(config.MaxAge)
+
+	return func(next enlight.HandleFunc) enlight.HandleFunc {
+		return func(c enlight.Context) error {
+			if config.Skipper(c) {
+				return next(c)
+			}
+
+			header := &c.Response().Header
+			origin := c.Peek(enlight.HeaderOrigin)
+			preflight := string(c.Request().Method()) == fasthttp.MethodOptions &&
+				c.Peek(enlight.HeaderAccessControlRequestMethod) != ""
+
+			// The response depends on the origin, unless any origin gets "*"
+			if !anyOrigin {
+				header.Add(enlight.HeaderVary, enlight.HeaderOrigin)
+			}
+			if preflight {
+				header.Add(enlight.HeaderVary, enlight.HeaderAccessControlRequestMethod)
+				header.Add(enlight.HeaderVary, enlight.HeaderAccessControlRequestHeaders)
+			}
+
+			allowOrigin := ""
+			switch {
+			case origin == "":
+			case anyOrigin:
+				allowOrigin = "*"
+			case matchOrigin(origin, config.AllowOrigins, patterns):
+				allowOrigin = origin
+			}
+
+			if !preflight {
+				if allowOrigin != "" {
+					header.Set(enlight.HeaderAccessControlAllowOrigin, allowOrigin)
+					if config.AllowCredentials {
+						header.Set(enlight.HeaderAccessControlAllowCredentials, "true")
+					}
+					if exposeHeaders != "" {
+						header.Set(enlight.HeaderAccessControlExposeHeaders, exposeHeaders)
+					}
+				}
+				return next(c)
+			}
+
+			// Preflight requests are answered without calling the handler,
+			// a browser blocks the request if the CORS headers are missing
+			if allowOrigin == "" {
+				return c.NoContent(fasthttp.StatusNoContent)
+			}
+			header.Set(enlight.HeaderAccessControlAllowOrigin, allowOrigin)
+			header.Set(enlight.HeaderAccessControlAllowMethods, allowMethods)
+			if config.AllowCredentials {
+				header.Set(enlight.HeaderAccessControlAllowCredentials, "true")
+			}
+			if allowHeaders != "" {
+				header.Set(enlight.HeaderAccessControlAllowHeaders, allowHeaders)
+			} else if h := c.Peek(enlight.HeaderAccessControlRequestHeaders); h != "" {
+				header.Set(enlight.HeaderAccessControlAllowHeaders, h)
+			}
+			if config.MaxAge > 0 {
+				header.Set(enlight.HeaderAccessControlMaxAge, maxAge)
+			}
+			return c.NoContent(fasthttp.StatusNoContent)
+		}
+	}
+}
+
+// matchOrigin reports if origin is one of the allowed origins or matches
+// one of the patterns.
+func matchOrigin(origin string, allowed []string, patterns []*regexp.Regexp) bool {
+	for _, o := range allowed {
+		if strings.EqualFold(o, origin) || matchSubdomain(origin, o) {
+			return true
+		}
+	}
+	for _, p := range patterns {
+		if p.MatchString(origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchSubdomain reports if origin matches a wildcard subdomain like
+// "https://*.example.com", which does not match "https://example.com".
+func matchSubdomain(origin, wildcard string) bool {
+	i := strings.Index(wildcard, "*.")
+	if i < 0 {
+		return false
+	}
+	prefix, suffix := strings.ToLower(wildcard[:i]), strings.ToLower(wildcard[i+1:])
+	origin = strings.ToLower(origin)
+	if len(origin) <= len(prefix)+len(suffix) ||
+		!strings.HasPrefix(origin, prefix) || !strings.HasSuffix(origin, suffix) {
+		return false
+	}
+	sub := origin[len(prefix) : len(origin)-len(suffix)]
+	return !strings.ContainsAny(sub, "/:@")
+}
@@ -0,0 +1,96 @@
+package middleware
+
+import (
+	"testing"
+
+	"github.com/juliankoehn/enlight"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCORS(t *testing.T) {
+	e := enlight.New()
+	e.Use(CORS())
+	e.GET("/", func(c enlight.Context) error {
+		return c.String(200, "ok")
+	})
+
+	ctx := request(e, "GET", "/", enlight.HeaderOrigin, "https://example.com")
+	assert.Equal(t, "*", string(ctx.Response.Header.Peek(enlight.HeaderAccessControlAllowOrigin)))
+	assert.Equal(t, "ok", string(ctx.Response.Body()))
+
+	// Preflight requests are answered without an OPTIONS route
+	ctx = request(e, "OPTIONS", "/",
+		enlight.HeaderOrigin, "https://example.com",
+		enlight.HeaderAccessControlRequestMethod, "PUT",
+		enlight.HeaderAccessControlRequestHeaders, "X-Token")
+	assert.Equal(t, 204, ctx.Response.StatusCode())
+	assert.Equal(t, "*", string(ctx.Response.Header.Peek(enlight.HeaderAccessControlAllowOrigin)))
+	assert.Equal(t, "GET,HEAD,PUT,PATCH,POST,DELETE", string(ctx.Response.Header.Peek(enlight.HeaderAccessControlAllowMethods)))
+	assert.Equal(t, "X-Token", string(ctx.Response.Header.Peek(enlight.HeaderAccessControlAllowHeaders)))
+}
+
+func TestCORSWithConfig(t *testing.T) {
+	e := enlight.New()
+	e.Use(CORSWithConfig(CORSConfig{
+		AllowOrigins:        []string{"https://example.com", "https://*.example.org"},
+		AllowOriginPatterns: []string{`https://app-\d+\.example\.net`},
+		AllowMethods:        []string{"GET", "POST"},
+		AllowHeaders:        []string{"Content-Type", "X-Token"},
+		AllowCredentials:    true,
+		ExposeHeaders:       []string{"X-Total"},
+		MaxAge:              600,
+	}))
+	e.GET("/", func(c enlight.Context) error {
+		return c.String(200, "ok")
+	})
+
+	origins := map[string]bool{
+		"https://example.com":                 true,
+		"https://EXAMPLE.com":                 true,
+		"https://api.example.org":             true,
+		"https://a.b.example.org":             true,
+		"https://app-42.example.net":          true,
+		"https://example.org":                 false,
+		"http://api.example.org":              false,
+		"https://evil.com/.example.org":       false,
+		"https://app-42.example.net.evil.com": false,
+		"https://example.com.evil.com":        false,
+	}
+	for origin, allowed := range origins {
+		ctx := request(e, "GET", "/", enlight.HeaderOrigin, origin)
+		if allowed {
+			assert.Equal(t, origin, string(ctx.Response.Header.Peek(enlight.HeaderAccessControlAllowOrigin)), origin)
+			assert.Equal(t, "true", string(ctx.Response.Header.Peek(enlight.HeaderAccessControlAllowCredentials)), origin)
+			assert.Equal(t, "X-Total", string(ctx.Response.Header.Peek(enlight.HeaderAccessControlExposeHeaders)), origin)
+		} else {
+			assert.Empty(t, ctx.Response.Header.Peek(enlight.HeaderAccessControlAllowOrigin), origin)
+		}
+		assert.Equal(t, enlight.HeaderOrigin, string(ctx.Response.Header.Peek(enlight.HeaderVary)), origin)
+		assert.Equal(t, "ok", string(ctx.Response.Body()), origin)
+	}
+
+	ctx := request(e, "OPTIONS", "/",
+		enlight.HeaderOrigin, "https://api.example.org",
+		enlight.HeaderAccessControlRequestMethod, "POST")
+	assert.Equal(t, 204, ctx.Response.StatusCode())
+	assert.Equal(t, "https://api.example.org", string(ctx.Response.Header.Peek(enlight.HeaderAccessControlAllowOrigin)))
+	assert.Equal(t, "GET,POST", string(ctx.Response.Header.Peek(enlight.HeaderAccessControlAllowMethods)))
+	assert.Equal(t, "Content-Type,X-Token", string(ctx.Response.Header.Peek(enlight.HeaderAccessControlAllowHeaders)))
+	assert.Equal(t, "600", string(ctx.Response.Header.Peek(enlight.HeaderAccessControlMaxAge)))
+
+	ctx = request(e, "OPTIONS", "/",
+		enlight.HeaderOrigin, "https://evil.com",
+		enlight.HeaderAccessControlRequestMethod, "POST")
+	assert.Equal(t, 204, ctx.Response.StatusCode())
+	assert.Empty(t, ctx.Response.Header.Peek(enlight.HeaderAccessControlAllowOrigin))
+	assert.Empty(t, ctx.Response.Header.Peek(enlight.HeaderAccessControlAllowMethods))
+
+	// Any origin with credentials would let every site make authenticated
+	// requests
+	assert.Panics(t, func() {
+		CORSWithConfig(CORSConfig{AllowCredentials: true})
+	})
+	assert.Panics(t, func() {
+		CORSWithConfig(CORSConfig{AllowOrigins: []string{"https://example.com", "*"}, AllowCredentials: true})
+	})
+}
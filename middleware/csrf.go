@@ -0,0 +1,216 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"encoding/base64"
+	"strings"
+
+	"github.com/juliankoehn/enlight"
+	"github.com/valyala/fasthttp"
+)
+
+type (
+	// CSRFConfig defines the config for CSRF middleware.
+	CSRFConfig struct {
+		// Skipper defines a function to skip middleware
+		Skipper Skipper
+
+		// TokenLength is the number of random bytes of a token.
+		// Optional. Default value 32.
+		TokenLength int `yaml:"token_length"`
+
+		// TokenLookup is a comma separated list of places the token is read
+		// from, the first one found is used. Places are given as
+		// "<source>:<name>", the sources are:
+		//
+		//	header: a request header, e.g. "header:X-CSRF-Token"
+		//	form: a form field, e.g. "form:_csrf"
+		//	query: a query parameter, e.g. "query:_csrf"
+		//
+		// Optional. Default value "header:X-CSRF-Token,form:_csrf".
+		TokenLookup string `yaml:"token_lookup"`
+
+		// ContextKey is the key of the token in the context store, templates
+		// can read it with {{ get "csrf" }}.
+		// Optional. Default value "csrf".
+		ContextKey string `yaml:"context_key"`
+
+		// CookieName is the name of the cookie holding the token.
+		// Optional. Default value "_csrf".
+		CookieName string `yaml:"cookie_name"`
+
+		// CookieDomain is the domain of the cookie.
+		// Optional. Default value "".
+		CookieDomain string `yaml:"cookie_domain"`
+
+		// CookiePath is the path of the cookie.
+		// Optional. Default value "/".
+		CookiePath string `yaml:"cookie_path"`
+
+		// CookieMaxAge is the lifetime of the cookie in seconds.
+		// Optional. Default value 86400 (24 hours).
+		CookieMaxAge int `yaml:"cookie_max_age"`
+
+		// CookieSameSite is the SameSite mode of the cookie.
+		// Optional. Default value fasthttp.CookieSameSiteLaxMode.
+		CookieSameSite fasthttp.CookieSameSite `yaml:"cookie_same_site"`
+
+		// CookieSecure only sends the cookie over HTTPS.
+		// Optional. Default value false.
+		CookieSecure bool `yaml:"cookie_secure"`
+
+		// CookieHTTPOnly hides the cookie from JavaScript. Scripts sending
+		// the token in a header have to read it from the page then.
+		// Optional. Default value false.
+		CookieHTTPOnly bool `yaml:"cookie_http_only"`
+	}
+
+	// csrfTokenExtractor returns the token sent with the request.
+	csrfTokenExtractor func(c enlight.Context) string
+)
+
+// Errors
+var (
+	ErrCSRFTokenMissing = enlight.NewHTTPError(fasthttp.StatusForbidden, "missing csrf token")
+	ErrCSRFTokenInvalid = enlight.NewHTTPError(fasthttp.StatusForbidden, "invalid csrf token")
+)
+
+var (
+	// DefaultCSRFConfig is the default CSRF middleware config.
+	DefaultCSRFConfig = CSRFConfig{
+		Skipper:        DefaultSkipper,
+		TokenLength:    32,
+		TokenLookup:    "header:" + enlight.HeaderXCSRFToken + ",form:_csrf",
+		ContextKey:     "csrf",
+		CookieName:     "_csrf",
+		CookiePath:     "/",
+		CookieMaxAge:   86400,
+		CookieSameSite: fasthttp.CookieSameSiteLaxMode,
+	}
+)
+
+// CSRF returns a Cross-Site Request Forgery protection middleware using a
+// double-submit cookie. The token is stored in a cookie and has to be sent
+// again with every unsafe request, which a foreign site cannot do as it
+// cannot read the cookie. Requests with the methods GET, HEAD, OPTIONS and
+// TRACE are not checked.
+func CSRF() enlight.MiddlewareFunc {
+	return CSRFWithConfig(DefaultCSRFConfig)
+}
+
+// CSRFWithConfig returns a CSRF middleware with config.
+// See: `CSRF()`.
+func CSRFWithConfig(config CSRFConfig) enlight.MiddlewareFunc {
+	// Defaults
+	if config.Skipper == nil {
+		config.Skipper = DefaultCSRFConfig.Skipper
+	}
+	if config.TokenLength == 0 {
+		config.TokenLength = DefaultCSRFConfig.TokenLength
+	}
+	if config.TokenLookup == "" {
+		config.TokenLookup = DefaultCSRFConfig.TokenLookup
+	}
+	if config.ContextKey == "" {
+		config.ContextKey = DefaultCSRFConfig.ContextKey
+	}
+	if config.CookieName == "" {
+		config.CookieName = DefaultCSRFConfig.CookieName
+	}
+	if config.CookiePath == "" {
+		config.CookiePath = DefaultCSRFConfig.CookiePath
+	}
+	if config.CookieMaxAge == 0 {
+		config.CookieMaxAge = DefaultCSRFConfig.CookieMaxAge
+	}
+	if config.CookieSameSite == fasthttp.CookieSameSiteDisabled {
+		config.CookieSameSite = DefaultCSRFConfig.CookieSameSite
+	}
+
+	var extractors []csrfTokenExtractor
+	for _, lookup := range strings.Split(config.TokenLookup, ",") {
+		parts := strings.SplitN(strings.TrimSpace(lookup), ":", 2)
+		if len(parts) != 2 {
+			panic("invalid csrf token lookup '" + lookup + "'")
+		}
+		extractors = append(extractors, csrfTokenFrom(parts[0], parts[1]))
+	}
+
+	return func(next enlight.HandleFunc) enlight.HandleFunc {
+		return func(c enlight.Context) error {
+			if config.Skipper(c) {
+				return next(c)
+			}
+
+			token := c.Cookie(config.CookieName)
+			if token == "" {
+				token = csrfToken(config.TokenLength)
+			}
+
+			switch string(c.Request().Method()) {
+			case fasthttp.MethodGet, fasthttp.MethodHead, fasthttp.MethodOptions, fasthttp.MethodTrace:
+			default:
+				sent := ""
+				for _, extract := range extractors {
+					if sent = extract(c); sent != "" {
+						break
+					}
+				}
+				if sent == "" {
+					return ErrCSRFTokenMissing
+				}
+				if subtle.ConstantTimeCompare([]byte(sent), []byte(c.Cookie(config.CookieName))) != 1 {
+					return ErrCSRFTokenInvalid
+				}
+			}
+
+			cookie := fasthttp.AcquireCookie()
+			cookie.SetKey(config.CookieName)
+			cookie.SetValue(token)
+			cookie.SetPath(config.CookiePath)
+			cookie.SetDomain(config.CookieDomain)
+			cookie.SetMaxAge(config.CookieMaxAge)
+			cookie.SetSameSite(config.CookieSameSite)
+			cookie.SetSecure(config.CookieSecure)
+			cookie.SetHTTPOnly(config.CookieHTTPOnly)
+			c.Response().Header.SetCookie(cookie)
+			fasthttp.ReleaseCookie(cookie)
+
+			c.Response().Header.Add(enlight.HeaderVary, enlight.HeaderCookie)
+			c.Set(config.ContextKey, token)
+			return next(c)
+		}
+	}
+}
+
+// csrfTokenFrom returns an extractor reading the token from source.
+func csrfTokenFrom(source, name string) csrfTokenExtractor {
+	switch source {
+	case "header":
+		return func(c enlight.Context) string {
+			return c.Peek(name)
+		}
+	case "form":
+		return func(c enlight.Context) string {
+			if v := c.Request().PostArgs().Peek(name); len(v) > 0 {
+				return string(v)
+			}
+			if form, err := c.Request().MultipartForm(); err == nil && len(form.Value[name]) > 0 {
+				return form.Value[name][0]
+			}
+			return ""
+		}
+	case "query":
+		return func(c enlight.Context) string {
+			return c.QueryParam(name)
+		}
+	}
+	panic("unknown csrf token source '" + source + "'")
+}
+
+// csrfToken returns a random token of length bytes.
+func csrfToken(length int) string {
+	b := make([]byte, length)
+	randomBytes(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
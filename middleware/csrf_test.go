@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"testing"
+
+	"github.com/juliankoehn/enlight"
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func TestCSRF(t *testing.T) {
+	e := enlight.New()
+	e.Use(CSRF())
+	e.GET("/form", func(c enlight.Context) error {
+		return c.String(200, c.GetString("csrf"))
+	})
+	e.POST("/form", func(c enlight.Context) error {
+		return c.String(200, "saved")
+	})
+
+	// Safe requests get a token
+	ctx := request(e, "GET", "/form")
+	token := string(ctx.Response.Body())
+	assert.Len(t, token, 43)
+	cookie := fasthttp.AcquireCookie()
+	defer fasthttp.ReleaseCookie(cookie)
+	cookie.SetKey("_csrf")
+	if assert.True(t, ctx.Response.Header.Cookie(cookie)) {
+		assert.Equal(t, token, string(cookie.Value()))
+		assert.Equal(t, "/", string(cookie.Path()))
+		assert.Equal(t, fasthttp.CookieSameSiteLaxMode, cookie.SameSite())
+	}
+
+	// The token of the cookie is kept
+	ctx = request(e, "GET", "/form", enlight.HeaderCookie, "_csrf="+token)
+	assert.Equal(t, token, string(ctx.Response.Body()))
+
+	ctx = request(e, "POST", "/form", enlight.HeaderCookie, "_csrf="+token, enlight.HeaderXCSRFToken, token)
+	assert.Equal(t, 200, ctx.Response.StatusCode())
+	assert.Equal(t, "saved", string(ctx.Response.Body()))
+
+	ctx = request(e, "POST", "/form", enlight.HeaderCookie, "_csrf="+token)
+	assert.Equal(t, 403, ctx.Response.StatusCode())
+
+	ctx = request(e, "POST", "/form", enlight.HeaderCookie, "_csrf="+token, enlight.HeaderXCSRFToken, "forged")
+	assert.Equal(t, 403, ctx.Response.StatusCode())
+
+	// Without a cookie, a sent token cannot match
+	ctx = request(e, "POST", "/form", enlight.HeaderXCSRFToken, token)
+	assert.Equal(t, 403, ctx.Response.StatusCode())
+}
+
+func TestCSRFTokenLookup(t *testing.T) {
+	e := enlight.New()
+	e.Use(CSRFWithConfig(CSRFConfig{
+		TokenLookup: "form:_csrf,query:csrf",
+		CookieName:  "xsrf",
+	}))
+	e.POST("/", func(c enlight.Context) error {
+		return c.NoContent(204)
+	})
+
+	ctx := &fasthttp.RequestCtx{}
+	ctx.Request.Header.SetMethod("POST")
+	ctx.Request.SetRequestURI("/")
+	ctx.Request.Header.SetContentType(enlight.MIMEApplicationForm)
+	ctx.Request.Header.Set(enlight.HeaderCookie, "xsrf=secret")
+	ctx.Request.SetBodyString("_csrf=secret")
+	e.ServeHTTP(ctx)
+	assert.Equal(t, 204, ctx.Response.StatusCode())
+
+	ctx = request(e, "POST", "/?csrf=secret", enlight.HeaderCookie, "xsrf=secret")
+	assert.Equal(t, 204, ctx.Response.StatusCode())
+
+	// The header is not looked up
+	ctx = request(e, "POST", "/", enlight.HeaderCookie, "xsrf=secret", enlight.HeaderXCSRFToken, "secret")
+	assert.Equal(t, 403, ctx.Response.StatusCode())
+}
@@ -0,0 +1,421 @@
+package middleware
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	json "github.com/json-iterator/go"
+	"github.com/juliankoehn/enlight"
+)
+
+type (
+	// LoggerConfig defines the config for Logger middleware.
+	LoggerConfig struct {
+		// Skipper defines a function to skip middleware
+		Skipper Skipper
+
+		// Format of the log lines, either FormatCombined, FormatJSON or a
+		// template with tags, e.g. "${remote_ip} ${method} ${uri} ${status}".
+		//
+		// Tags:
+		//
+		//	time_rfc3339, time_unix, id, remote_ip, host, method, uri, path,
+		//	route, protocol, referer, user_agent, status, error, latency
+		//	(nanoseconds), latency_human, bytes_in, bytes_out,
+		//	header:<NAME>, query:<NAME>
+		//
+		// Optional. Default value FormatCombined.
+		Format string `yaml:"format"`
+
+		// Output is the writer log lines are written to, once per request.
+		// Optional. Default value DefaultLogWriter.
+		Output io.Writer
+	}
+
+	// AsyncWriter writes to an io.Writer in a background goroutine, so
+	// writes never block the caller. Writes are queued and dropped while
+	// the queue is full. The goroutine starts with the first write. The
+	// writer must be closed to write the queued data and stop the
+	// goroutine, e.g. from an `Enlight#OnShutdownComplete()` hook.
+	AsyncWriter struct {
+		w       *bufio.Writer
+		queue   chan []byte
+		done    chan struct{}
+		start   sync.Once
+		mutex   sync.RWMutex
+		closed  bool
+		dropped uint64
+	}
+
+	// logTag writes the value of a template tag.
+	logTag func(buf *bytes.Buffer, e *logEntry)
+
+	// logEntry holds the values of a request logged by the Logger middleware.
+	logEntry struct {
+		c       enlight.Context
+		start   time.Time
+		latency time.Duration
+		status  int
+		err     error
+	}
+)
+
+// Formats
+const (
+	// FormatCombined is the Apache combined log format.
+	FormatCombined = "combined"
+	// FormatJSON writes a JSON object per request.
+	FormatJSON = "json"
+)
+
+var (
+	// DefaultLoggerConfig is the default Logger middleware config.
+	DefaultLoggerConfig = LoggerConfig{
+		Skipper: DefaultSkipper,
+		Format:  FormatCombined,
+		Output:  DefaultLogWriter,
+	}
+
+	// DefaultAsyncWriterSize is the number of lines queued by the
+	// DefaultLogWriter.
+	DefaultAsyncWriterSize = 1024
+
+	// DefaultLogWriter writes the lines of the Logger middleware to
+	// os.Stdout without blocking requests. Close it on shutdown to write
+	// the queued lines.
+	DefaultLogWriter = NewAsyncWriter(os.Stdout, DefaultAsyncWriterSize)
+)
+
+// Logger returns a middleware which writes an access log line per request
+// to the DefaultLogWriter.
+func Logger() enlight.MiddlewareFunc {
+	return LoggerWithConfig(DefaultLoggerConfig)
+}
+
+// LoggerWithConfig returns a Logger middleware with config.
+// See: `Logger()`.
+func LoggerWithConfig(config LoggerConfig) enlight.MiddlewareFunc {
+	// Defaults
+	if config.Skipper == nil {
+		config.Skipper = DefaultLoggerConfig.Skipper
+	}
+	if config.Format == "" {
+		config.Format = DefaultLoggerConfig.Format
+	}
+	if config.Output == nil {
+		config.Output = DefaultLoggerConfig.Output
+	}
+
+	var write func(buf *bytes.Buffer, e *logEntry)
+	switch config.Format {
+	case FormatCombined:
+		write = writeCombined
+	case FormatJSON:
+		write = writeJSON
+	default:
+		write = compileLogTemplate(config.Format)
+	}
+
+	pool := sync.Pool{
+		New: func() interface{} {
+			return new(bytes.Buffer)
+		},
+	}
+
+	return func(next enlight.HandleFunc) enlight.HandleFunc {
+		return func(c enlight.Context) error {
+			if config.Skipper(c) {
+				return next(c)
+			}
+
+			e := logEntry{c: c, start: time.Now()}
+			if e.err = next(c); e.err != nil {
+				// Handle the error here to log the status sent
+				c.Error(e.err)
+			}
+			e.latency = time.Since(e.start)
+			e.status = c.Response().StatusCode()
+
+			buf := pool.Get().(*bytes.Buffer)
+			buf.Reset()
+			write(buf, &e)
+			buf.WriteByte('\n')
+			config.Output.Write(buf.Bytes())
+			pool.Put(buf)
+			return nil
+		}
+	}
+}
+
+// NewAsyncWriter returns an AsyncWriter queueing up to size writes to w.
+func NewAsyncWriter(w io.Writer, size int) *AsyncWriter {
+	return &AsyncWriter{
+		w:     bufio.NewWriter(w),
+		queue: make(chan []byte, size),
+		done:  make(chan struct{}),
+	}
+}
+
+// Write queues a copy of p. It never blocks and always succeeds, p is
+// dropped if the queue is full or the writer is closed.
+func (a *AsyncWriter) Write(p []byte) (int, error) {
+	a.mutex.RLock()
+	defer a.mutex.RUnlock()
+	if a.closed {
+		atomic.AddUint64(&a.dropped, 1)
+		return len(p), nil
+	}
+	a.start.Do(func() {
+		go a.run()
+	})
+
+	select {
+	case a.queue <- append([]byte(nil), p...):
+	default:
+		atomic.AddUint64(&a.dropped, 1)
+	}
+	return len(p), nil
+}
+
+// Close writes the queued data and stops the writer.
+func (a *AsyncWriter) Close() error {
+	a.start.Do(func() {
+		go a.run()
+	})
+	a.mutex.Lock()
+	if !a.closed {
+		a.closed = true
+		close(a.queue)
+	}
+	a.mutex.Unlock()
+
+	<-a.done
+	return nil
+}
+
+// Dropped returns the number of writes dropped so far.
+func (a *AsyncWriter) Dropped() uint64 {
+	return atomic.LoadUint64(&a.dropped)
+}
+
+func (a *AsyncWriter) run() {
+	defer close(a.done)
+	for p := range a.queue {
+		a.w.Write(p)
+		// Flush once the queue is drained
+		if len(a.queue) == 0 {
+			a.w.Flush()
+		}
+	}
+	a.w.Flush()
+}
+
+func writeCombined(buf *bytes.Buffer, e *logEntry) {
+	req := &e.c.Request().Request
+	buf.WriteString(e.c.RealIP())
+	buf.WriteString(" - - [")
+	buf.WriteString(e.start.Format("02/Jan/2006:15:04:05 -0700"))
+	buf.WriteString(`] "`)
+	buf.Write(req.Header.Method())
+	buf.WriteByte(' ')
+	buf.Write(req.Header.RequestURI())
+	buf.WriteByte(' ')
+	buf.WriteString(protocol(e.c))
+	buf.WriteString(`" `)
+	buf.WriteString(strconv.Itoa(e.status))
+	buf.WriteByte(' ')
+	if n := bytesOut(e.c); n > 0 {
+		buf.WriteString(strconv.Itoa(n))
+	} else {
+		buf.WriteByte('-')
+	}
+	buf.WriteString(` "`)
+	buf.WriteString(escapeQuotes(string(req.Header.Referer())))
+	buf.WriteString(`" "`)
+	buf.WriteString(escapeQuotes(string(req.Header.UserAgent())))
+	buf.WriteByte('"')
+}
+
+func writeJSON(buf *bytes.Buffer, e *logEntry) {
+	req := &e.c.Request().Request
+	entry := enlight.Map{
+		"time":          e.start.Format(time.RFC3339Nano),
+		"id":            e.c.RequestID(),
+		"remote_ip":     e.c.RealIP(),
+		"host":          string(req.Host()),
+		"method":        string(req.Header.Method()),
+		"uri":           string(req.Header.RequestURI()),
+		"route":         e.c.Path(),
+		"protocol":      protocol(e.c),
+		"status":        e.status,
+		"latency":       int64(e.latency),
+		"latency_human": e.latency.String(),
+		"bytes_in":      len(req.Body()),
+		"bytes_out":     bytesOut(e.c),
+		"referer":       string(req.Header.Referer()),
+		"user_agent":    string(req.Header.UserAgent()),
+	}
+	if e.err != nil {
+		entry["error"] = e.err.Error()
+	}
+	b, _ := json.Marshal(entry)
+	buf.Write(b)
+}
+
+// compileLogTemplate splits the template into text and tags once, so
+// writing a line does not parse the template.
+func compileLogTemplate(template string) func(buf *bytes.Buffer, e *logEntry) {
+	var parts []logTag
+	for {
+		start := strings.Index(template, "${")
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(template[start:], '}')
+		if end < 0 {
+			break
+		}
+		text, tag := template[:start], template[start+2:start+end]
+		parts = append(parts, func(buf *bytes.Buffer, _ *logEntry) {
+			buf.WriteString(text)
+		}, logTagFunc(tag))
+		template = template[start+end+1:]
+	}
+	if template != "" {
+		text := template
+		parts = append(parts, func(buf *bytes.Buffer, _ *logEntry) {
+			buf.WriteString(text)
+		})
+	}
+
+	return func(buf *bytes.Buffer, e *logEntry) {
+		for _, part := range parts {
+			part(buf, e)
+		}
+	}
+}
+
+func logTagFunc(tag string) logTag {
+	switch {
+	case strings.HasPrefix(tag, "header:"):
+		name := tag[len("header:"):]
+		return func(buf *bytes.Buffer, e *logEntry) {
+			buf.Write(e.c.Request().Request.Header.Peek(name))
+		}
+	case strings.HasPrefix(tag, "query:"):
+		name := tag[len("query:"):]
+		return func(buf *bytes.Buffer, e *logEntry) {
+			buf.WriteString(e.c.QueryParam(name))
+		}
+	}
+
+	switch tag {
+	case "time_rfc3339":
+		return func(buf *bytes.Buffer, e *logEntry) {
+			buf.WriteString(e.start.Format(time.RFC3339))
+		}
+	case "time_unix":
+		return func(buf *bytes.Buffer, e *logEntry) {
+			buf.WriteString(strconv.FormatInt(e.start.Unix(), 10))
+		}
+	case "id":
+		return func(buf *bytes.Buffer, e *logEntry) {
+			buf.WriteString(e.c.RequestID())
+		}
+	case "remote_ip":
+		return func(buf *bytes.Buffer, e *logEntry) {
+			buf.WriteString(e.c.RealIP())
+		}
+	case "host":
+		return func(buf *bytes.Buffer, e *logEntry) {
+			buf.Write(e.c.Request().Request.Host())
+		}
+	case "method":
+		return func(buf *bytes.Buffer, e *logEntry) {
+			buf.Write(e.c.Request().Method())
+		}
+	case "uri":
+		return func(buf *bytes.Buffer, e *logEntry) {
+			buf.Write(e.c.Request().RequestURI())
+		}
+	case "path":
+		return func(buf *bytes.Buffer, e *logEntry) {
+			buf.Write(e.c.Request().Path())
+		}
+	case "route":
+		return func(buf *bytes.Buffer, e *logEntry) {
+			buf.WriteString(e.c.Path())
+		}
+	case "protocol":
+		return func(buf *bytes.Buffer, e *logEntry) {
+			buf.WriteString(protocol(e.c))
+		}
+	case "referer":
+		return func(buf *bytes.Buffer, e *logEntry) {
+			buf.Write(e.c.Request().Referer())
+		}
+	case "user_agent":
+		return func(buf *bytes.Buffer, e *logEntry) {
+			buf.Write(e.c.Request().UserAgent())
+		}
+	case "status":
+		return func(buf *bytes.Buffer, e *logEntry) {
+			buf.WriteString(strconv.Itoa(e.status))
+		}
+	case "error":
+		return func(buf *bytes.Buffer, e *logEntry) {
+			if e.err != nil {
+				buf.WriteString(e.err.Error())
+			}
+		}
+	case "latency":
+		return func(buf *bytes.Buffer, e *logEntry) {
+			buf.WriteString(strconv.FormatInt(int64(e.latency), 10))
+		}
+	case "latency_human":
+		return func(buf *bytes.Buffer, e *logEntry) {
+			buf.WriteString(e.latency.String())
+		}
+	case "bytes_in":
+		return func(buf *bytes.Buffer, e *logEntry) {
+			buf.WriteString(strconv.Itoa(len(e.c.Request().Request.Body())))
+		}
+	case "bytes_out":
+		return func(buf *bytes.Buffer, e *logEntry) {
+			buf.WriteString(strconv.Itoa(bytesOut(e.c)))
+		}
+	}
+	panic("unknown log tag '" + tag + "'")
+}
+
+// protocol returns the HTTP version of the request.
+func protocol(c enlight.Context) string {
+	if c.Request().Request.Header.IsHTTP11() {
+		return "HTTP/1.1"
+	}
+	return "HTTP/1.0"
+}
+
+// bytesOut returns the size of the response body, which is unknown for
+// streamed responses.
+func bytesOut(c enlight.Context) int {
+	res := c.Response()
+	if res.IsBodyStream() {
+		if n := res.Header.ContentLength(); n > 0 {
+			return n
+		}
+		return 0
+	}
+	return len(res.Body())
+}
+
+func escapeQuotes(s string) string {
+	return strings.Replace(s, `"`, `\"`, -1)
+}
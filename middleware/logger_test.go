@@ -0,0 +1,111 @@
+package middleware
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+
+	json "github.com/json-iterator/go"
+	"github.com/juliankoehn/enlight"
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func request(e *enlight.Enlight, method, uri string, headers ...string) *fasthttp.RequestCtx {
+	ctx := &fasthttp.RequestCtx{}
+	req := &fasthttp.Request{}
+	req.Header.SetMethod(method)
+	req.SetRequestURI(uri)
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	ctx.Init(req, &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1)}, nil)
+	e.ServeHTTP(ctx)
+	return ctx
+}
+
+func TestLogger(t *testing.T) {
+	buf := new(bytes.Buffer)
+	e := enlight.New()
+	e.Logger = enlight.NewLogger(new(bytes.Buffer))
+	e.Use(LoggerWithConfig(LoggerConfig{Output: buf}))
+	e.GET("/users/:id", func(c enlight.Context) error {
+		return c.String(200, "Jon Snow")
+	})
+
+	request(e, "GET", "/users/1?tab=posts", "Referer", "http://example.com", "User-Agent", `curl "7"`)
+	line := buf.String()
+	assert.True(t, strings.HasPrefix(line, "10.0.0.1 - - ["), line)
+	assert.True(t, strings.HasSuffix(line, `] "GET /users/1?tab=posts HTTP/1.1" 200 8 "http://example.com" "curl \"7\""`+"\n"), line)
+}
+
+func TestLoggerJSON(t *testing.T) {
+	buf := new(bytes.Buffer)
+	e := enlight.New()
+	e.Logger = enlight.NewLogger(new(bytes.Buffer))
+	_, proxies, _ := net.ParseCIDR("10.0.0.0/8")
+	e.TrustedProxies = []*net.IPNet{proxies}
+	e.Use(LoggerWithConfig(LoggerConfig{Format: FormatJSON, Output: buf}), RequestID())
+	e.POST("/users/:id", func(c enlight.Context) error {
+		return errors.New("database down")
+	})
+
+	ctx := request(e, "POST", "/users/1", enlight.HeaderXForwardedFor, "203.0.113.7, 10.0.0.2", enlight.HeaderXRequestID, "abc")
+	assert.Equal(t, 500, ctx.Response.StatusCode())
+
+	var entry map[string]interface{}
+	if assert.NoError(t, json.Unmarshal(buf.Bytes(), &entry)) {
+		assert.Equal(t, "abc", entry["id"])
+		assert.Equal(t, "203.0.113.7", entry["remote_ip"])
+		assert.Equal(t, "/users/:id", entry["route"])
+		assert.Equal(t, "/users/1", entry["uri"])
+		assert.Equal(t, float64(500), entry["status"])
+		assert.Equal(t, "database down", entry["error"])
+		assert.NotZero(t, entry["latency"])
+	}
+}
+
+func TestLoggerTemplate(t *testing.T) {
+	buf := new(bytes.Buffer)
+	e := enlight.New()
+	e.Use(LoggerWithConfig(LoggerConfig{
+		Format: "${method} ${route} ${status} ${header:X-Tenant} q=${query:q} ${bytes_out}",
+		Output: buf,
+		Skipper: func(c enlight.Context) bool {
+			return string(c.Request().Path()) == "/health"
+		},
+	}))
+	e.GET("/search", func(c enlight.Context) error {
+		return c.String(200, "ok")
+	})
+	e.GET("/health", func(c enlight.Context) error {
+		return c.NoContent(204)
+	})
+
+	request(e, "GET", "/health")
+	request(e, "GET", "/search?q=go", "X-Tenant", "acme")
+	assert.Equal(t, "GET /search 200 acme q=go 2\n", buf.String())
+
+	assert.Panics(t, func() {
+		LoggerWithConfig(LoggerConfig{Format: "${unknown}"})
+	})
+}
+
+func TestAsyncWriter(t *testing.T) {
+	buf := new(bytes.Buffer)
+	w := NewAsyncWriter(buf, 100)
+	for i := 0; i < 10; i++ {
+		w.Write([]byte("line\n"))
+	}
+	assert.NoError(t, w.Close())
+	assert.Equal(t, strings.Repeat("line\n", 10), buf.String())
+
+	// Writes after Close are dropped
+	w.Write([]byte("late\n"))
+	assert.Equal(t, uint64(1), w.Dropped())
+
+	// A writer which never wrote closes too
+	assert.NoError(t, NewAsyncWriter(buf, 100).Close())
+}
@@ -70,7 +70,7 @@ func RecoverWithConfig(config RecoverConfig) enlight.MiddlewareFunc {
 					stack := make([]byte, config.StackSize)
 					length := runtime.Stack(stack, !config.DisableStackAll)
 					if !config.DisablePrintStack {
-						fmt.Printf("[PANIC RECOVER] %v %s\n", err, stack[:length])
+						c.Logger().Error("panic recovered", "error", err, "stack", string(stack[:length]))
 					}
 					c.Error(err)
 				}
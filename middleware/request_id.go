@@ -0,0 +1,154 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/juliankoehn/enlight"
+)
+
+type (
+	// RequestIDConfig defines the config for RequestID middleware.
+	RequestIDConfig struct {
+		// Skipper defines a function to skip middleware
+		Skipper Skipper
+
+		// Generator generates the ID of requests without a valid incoming ID.
+		// Optional. Default value UUIDv4.
+		Generator func() string
+
+		// IgnoreIncoming always generates a new ID instead of accepting the
+		// X-Request-ID header sent by the client.
+		// Optional. Default value false.
+		IgnoreIncoming bool `yaml:"ignore_incoming"`
+	}
+)
+
+// maxRequestIDLength is the maximum length of an accepted incoming ID.
+const maxRequestIDLength = 128
+
+// crockford is the Crockford base32 alphabet used by ULIDs.
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+var (
+	// DefaultRequestIDConfig is the default RequestID middleware config.
+	DefaultRequestIDConfig = RequestIDConfig{
+		Skipper:   DefaultSkipper,
+		Generator: UUIDv4,
+	}
+)
+
+// RequestID returns a middleware which sets the X-Request-ID response header
+// to the ID sent by the client or a generated one. The ID is available with
+// `Context#RequestID()`.
+func RequestID() enlight.MiddlewareFunc {
+	return RequestIDWithConfig(DefaultRequestIDConfig)
+}
+
+// RequestIDWithConfig returns a RequestID middleware with config.
+// See: `RequestID()`.
+func RequestIDWithConfig(config RequestIDConfig) enlight.MiddlewareFunc {
+	// Defaults
+	if config.Skipper == nil {
+		config.Skipper = DefaultRequestIDConfig.Skipper
+	}
+	if config.Generator == nil {
+		config.Generator = DefaultRequestIDConfig.Generator
+	}
+
+	return func(next enlight.HandleFunc) enlight.HandleFunc {
+		return func(c enlight.Context) error {
+			if config.Skipper(c) {
+				return next(c)
+			}
+
+			id := ""
+			if !config.IgnoreIncoming {
+				id = c.Peek(enlight.HeaderXRequestID)
+			}
+			if !validRequestID(id) {
+				id = config.Generator()
+			}
+			c.Response().Header.Set(enlight.HeaderXRequestID, id)
+			c.Set(enlight.ContextKeyRequestID, id)
+
+			return next(c)
+		}
+	}
+}
+
+// validRequestID reports if id is short and only contains characters
+// which are safe to log.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		b := id[i]
+		if !(b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' || b >= '0' && b <= '9' ||
+			b == '-' || b == '_' || b == '.' || b == ':') {
+			return false
+		}
+	}
+	return true
+}
+
+// UUIDv4 returns a random UUID (version 4), e.g.
+// "0b6b3f3e-4f1a-4c1e-9f3e-8c1b5a7d2e4f".
+func UUIDv4() string {
+	var u [16]byte
+	randomBytes(u[:])
+	u[6] = (u[6] & 0x0f) | 0x40 // version 4
+	u[8] = (u[8] & 0x3f) | 0x80 // variant 10
+
+	b := make([]byte, 36)
+	hex.Encode(b[0:8], u[0:4])
+	b[8] = '-'
+	hex.Encode(b[9:13], u[4:6])
+	b[13] = '-'
+	hex.Encode(b[14:18], u[6:8])
+	b[18] = '-'
+	hex.Encode(b[19:23], u[8:10])
+	b[23] = '-'
+	hex.Encode(b[24:], u[10:])
+	return string(b)
+}
+
+// ULID returns a Universally Unique Lexicographically Sortable Identifier,
+// e.g. "01ARZ3NDEKTSV4RRFFQ69G5FAV". ULIDs sort by the time they were
+// generated in milliseconds.
+func ULID() string {
+	var u [16]byte
+	ms := uint64(time.Now().UnixNano() / int64(time.Millisecond))
+	for i := 5; i >= 0; i-- {
+		u[i] = byte(ms)
+		ms >>= 8
+	}
+	randomBytes(u[6:])
+
+	// Encode the 128 bits as 26 characters of 5 bits, the first character
+	// only holds the 3 most significant bits.
+	b := make([]byte, 26)
+	var acc uint64
+	var bits uint
+	n := 25
+	for i := 15; i >= 0; i-- {
+		acc |= uint64(u[i]) << bits
+		bits += 8
+		for bits >= 5 {
+			b[n] = crockford[acc&31]
+			n--
+			acc >>= 5
+			bits -= 5
+		}
+	}
+	b[0] = crockford[acc&31]
+	return string(b)
+}
+
+func randomBytes(b []byte) {
+	if _, err := rand.Read(b); err != nil {
+		panic("middleware: reading random bytes failed: " + err.Error())
+	}
+}
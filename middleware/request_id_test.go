@@ -0,0 +1,117 @@
+package middleware
+
+import (
+	"bytes"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	json "github.com/json-iterator/go"
+	"github.com/juliankoehn/enlight"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestID(t *testing.T) {
+	logs := new(bytes.Buffer)
+	e := enlight.New()
+	e.Logger = enlight.NewLogger(logs)
+	e.Use(RequestID())
+	e.GET("/", func(c enlight.Context) error {
+		return c.String(200, c.RequestID())
+	})
+	e.GET("/fail", func(c enlight.Context) error {
+		return enlight.NewHTTPError(500, "failed")
+	})
+	e.GET("/invalid", func(c enlight.Context) error {
+		return enlight.NewHTTPError(422, enlight.ValidationErrors{"city": {"is required"}})
+	})
+	e.GET("/items", func(c enlight.Context) error {
+		return enlight.NewHTTPError(409, []string{"a", "b"})
+	})
+
+	ctx := request(e, "GET", "/")
+	id := string(ctx.Response.Header.Peek(enlight.HeaderXRequestID))
+	assert.Regexp(t, `^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, id)
+	assert.Equal(t, id, string(ctx.Response.Body()))
+
+	// Incoming IDs are accepted if they are safe to log
+	ctx = request(e, "GET", "/", enlight.HeaderXRequestID, "upstream-42")
+	assert.Equal(t, "upstream-42", string(ctx.Response.Body()))
+	ctx = request(e, "GET", "/", enlight.HeaderXRequestID, "evil\" id")
+	assert.NotEqual(t, "evil\" id", string(ctx.Response.Body()))
+
+	// Without the middleware the incoming header is not used
+	e2 := enlight.New()
+	e2.GET("/", func(c enlight.Context) error {
+		return c.String(200, c.RequestID())
+	})
+	ctx = request(e2, "GET", "/", enlight.HeaderXRequestID, "evil\" id")
+	assert.Empty(t, ctx.Response.Body())
+
+	// The error handler includes the ID
+	ctx = request(e, "GET", "/fail", enlight.HeaderXRequestID, "req-1")
+	var body map[string]string
+	if assert.NoError(t, json.Unmarshal(ctx.Response.Body(), &body)) {
+		assert.Equal(t, "req-1", body["request_id"])
+	}
+	assert.Contains(t, logs.String(), "request_id=req-1")
+
+	// Messages which are not a string include the ID too
+	ctx = request(e, "GET", "/invalid", enlight.HeaderXRequestID, "req-2")
+	assert.JSONEq(t, `{"city":["is required"],"request_id":"req-2"}`, string(ctx.Response.Body()))
+	ctx = request(e, "GET", "/items", enlight.HeaderXRequestID, "req-3")
+	assert.JSONEq(t, `{"message":["a","b"],"request_id":"req-3"}`, string(ctx.Response.Body()))
+}
+
+func TestRequestIDLogger(t *testing.T) {
+	logs := new(bytes.Buffer)
+	e := enlight.New()
+	e.Logger = enlight.NewLogger(logs)
+	// The logger of the context is fetched before the ID is set
+	e.Use(func(next enlight.HandleFunc) enlight.HandleFunc {
+		return func(c enlight.Context) error {
+			c.Logger().Info("before")
+			return next(c)
+		}
+	}, RequestID())
+	e.GET("/", func(c enlight.Context) error {
+		c.Logger().Info("handled")
+		return c.NoContent(204)
+	})
+
+	request(e, "GET", "/", enlight.HeaderXRequestID, "req-1")
+	lines := strings.Split(strings.TrimSpace(logs.String()), "\n")
+	if assert.Len(t, lines, 2) {
+		assert.NotContains(t, lines[0], "request_id")
+		assert.Contains(t, lines[1], "request_id=req-1")
+	}
+}
+
+func TestRequestIDWithConfig(t *testing.T) {
+	e := enlight.New()
+	e.Use(RequestIDWithConfig(RequestIDConfig{
+		Generator:      func() string { return "generated" },
+		IgnoreIncoming: true,
+	}))
+	e.GET("/", func(c enlight.Context) error {
+		return c.String(200, c.RequestID())
+	})
+
+	ctx := request(e, "GET", "/", enlight.HeaderXRequestID, "upstream-42")
+	assert.Equal(t, "generated", string(ctx.Response.Body()))
+}
+
+func TestULID(t *testing.T) {
+	ids := make([]string, 100)
+	for i := range ids {
+		ids[i] = ULID()
+	}
+	for _, id := range ids {
+		assert.Regexp(t, regexp.MustCompile(`^[0-7][0-9A-HJKMNP-TV-Z]{25}$`), id)
+	}
+	// The time prefix sorts
+	assert.True(t, sort.SliceIsSorted(ids, func(i, j int) bool {
+		return strings.Compare(ids[i][:10], ids[j][:10]) < 0
+	}))
+}
@@ -0,0 +1,208 @@
+package middleware
+
+import (
+	"encoding/base64"
+	"strconv"
+	"strings"
+
+	"github.com/juliankoehn/enlight"
+)
+
+type (
+	// SecureConfig defines the config for Secure middleware. Empty values
+	// get the default, a header is omitted with its Disable option.
+	SecureConfig struct {
+		// Skipper defines a function to skip middleware
+		Skipper Skipper
+
+		// XSSProtection sets the X-XSS-Protection header, which stops pages
+		// from loading in older browsers when they detect reflected XSS.
+		// Optional. Default value "1; mode=block".
+		XSSProtection string `yaml:"xss_protection"`
+
+		// DisableXSSProtection omits the X-XSS-Protection header.
+		// Optional. Default value false.
+		DisableXSSProtection bool `yaml:"disable_xss_protection"`
+
+		// ContentTypeNosniff sets the X-Content-Type-Options header, which
+		// stops browsers from guessing the content type.
+		// Optional. Default value "nosniff".
+		ContentTypeNosniff string `yaml:"content_type_nosniff"`
+
+		// DisableContentTypeNosniff omits the X-Content-Type-Options header.
+		// Optional. Default value false.
+		DisableContentTypeNosniff bool `yaml:"disable_content_type_nosniff"`
+
+		// XFrameOptions sets the X-Frame-Options header, which controls if
+		// the page may be rendered in a frame, e.g. "DENY".
+		// Optional. Default value "SAMEORIGIN".
+		XFrameOptions string `yaml:"x_frame_options"`
+
+		// DisableXFrameOptions omits the X-Frame-Options header.
+		// Optional. Default value false.
+		DisableXFrameOptions bool `yaml:"disable_x_frame_options"`
+
+		// HSTSMaxAge sets the max-age in seconds of the Strict-Transport-Security
+		// header, which tells browsers to only use HTTPS. It is only sent on
+		// HTTPS requests, X-Forwarded-Proto is only believed if it was set by
+		// one of the `Enlight#TrustedProxies`. Set it to a negative value to
+		// omit the header.
+		// Optional. Default value 31536000 (one year).
+		HSTSMaxAge int `yaml:"hsts_max_age"`
+
+		// HSTSExcludeSubdomains omits includeSubdomains from the
+		// Strict-Transport-Security header.
+		// Optional. Default value false.
+		HSTSExcludeSubdomains bool `yaml:"hsts_exclude_subdomains"`
+
+		// HSTSPreloadEnabled adds preload to the Strict-Transport-Security
+		// header, see https://hstspreload.org.
+		// Optional. Default value false.
+		HSTSPreloadEnabled bool `yaml:"hsts_preload_enabled"`
+
+		// ContentSecurityPolicy sets the Content-Security-Policy header. The
+		// placeholder ${nonce} is replaced with a random nonce per request,
+		// e.g. "script-src 'nonce-${nonce}'", which is stored in the context
+		// under NonceContextKey.
+		// Optional. Default value "".
+		ContentSecurityPolicy string `yaml:"content_security_policy"`
+
+		// CSPReportOnly sends the policy in the
+		// Content-Security-Policy-Report-Only header, so violations are
+		// reported but not blocked.
+		// Optional. Default value false.
+		CSPReportOnly bool `yaml:"csp_report_only"`
+
+		// NonceContextKey is the key of the CSP nonce in the context store,
+		// templates can read it with {{ get "csp_nonce" }}.
+		// Optional. Default value "csp_nonce".
+		NonceContextKey string `yaml:"nonce_context_key"`
+
+		// ReferrerPolicy sets the Referrer-Policy header.
+		// Optional. Default value "strict-origin-when-cross-origin".
+		ReferrerPolicy string `yaml:"referrer_policy"`
+
+		// DisableReferrerPolicy omits the Referrer-Policy header.
+		// Optional. Default value false.
+		DisableReferrerPolicy bool `yaml:"disable_referrer_policy"`
+	}
+)
+
+// cspNonce is the placeholder of the nonce in the Content-Security-Policy.
+const cspNonce = "${nonce}"
+
+var (
+	// DefaultSecureConfig is the default Secure middleware config.
+	DefaultSecureConfig = SecureConfig{
+		Skipper:            DefaultSkipper,
+		XSSProtection:      "1; mode=block",
+		ContentTypeNosniff: "nosniff",
+		XFrameOptions:      "SAMEORIGIN",
+		HSTSMaxAge:         31536000,
+		NonceContextKey:    "csp_nonce",
+		ReferrerPolicy:     "strict-origin-when-cross-origin",
+	}
+)
+
+// Secure returns a middleware which sets security headers against
+// cross-site scripting, clickjacking, content sniffing and protocol
+// downgrades.
+func Secure() enlight.MiddlewareFunc {
+	return SecureWithConfig(DefaultSecureConfig)
+}
+
+// SecureWithConfig returns a Secure middleware with config.
+// See: `Secure()`.
+func SecureWithConfig(config SecureConfig) enlight.MiddlewareFunc {
+	// Defaults
+	if config.Skipper == nil {
+		config.Skipper = DefaultSecureConfig.Skipper
+	}
+	if config.XSSProtection == "" {
+		config.XSSProtection = DefaultSecureConfig.XSSProtection
+	}
+	if config.ContentTypeNosniff == "" {
+		config.ContentTypeNosniff = DefaultSecureConfig.ContentTypeNosniff
+	}
+	if config.XFrameOptions == "" {
+		config.XFrameOptions = DefaultSecureConfig.XFrameOptions
+	}
+	if config.ReferrerPolicy == "" {
+		config.ReferrerPolicy = DefaultSecureConfig.ReferrerPolicy
+	}
+	if config.HSTSMaxAge == 0 {
+		config.HSTSMaxAge = DefaultSecureConfig.HSTSMaxAge
+	}
+	if config.NonceContextKey == "" {
+		config.NonceContextKey = DefaultSecureConfig.NonceContextKey
+	}
+
+	hsts := ""
+	if config.HSTSMaxAge > 0 {
+		hsts = "max-age=" + strconv.Itoa(config.HSTSMaxAge)
+		if !config.HSTSExcludeSubdomains {
+			hsts += "; includeSubdomains"
+		}
+		if config.HSTSPreloadEnabled {
+			hsts += "; preload"
+		}
+	}
+	cspHeader := enlight.HeaderContentSecurityPolicy
+	if config.CSPReportOnly {
+		cspHeader = enlight.HeaderContentSecurityPolicyReportOnly
+	}
+	nonce := strings.Contains(config.ContentSecurityPolicy, cspNonce)
+
+	return func(next enlight.HandleFunc) enlight.HandleFunc {
+		return func(c enlight.Context) error {
+			if config.Skipper(c) {
+				return next(c)
+			}
+
+			header := &c.Response().Header
+			if !config.DisableXSSProtection {
+				header.Set(enlight.HeaderXXSSProtection, config.XSSProtection)
+			}
+			if !config.DisableContentTypeNosniff {
+				header.Set(enlight.HeaderXContentTypeOptions, config.ContentTypeNosniff)
+			}
+			if !config.DisableXFrameOptions {
+				header.Set(enlight.HeaderXFrameOptions, config.XFrameOptions)
+			}
+			if hsts != "" && isHTTPS(c) {
+				header.Set(enlight.HeaderStrictTransportSecurity, hsts)
+			}
+			if config.ContentSecurityPolicy != "" {
+				csp := config.ContentSecurityPolicy
+				if nonce {
+					n := CSPNonce()
+					c.Set(config.NonceContextKey, n)
+					csp = strings.Replace(csp, cspNonce, n, -1)
+				}
+				header.Set(cspHeader, csp)
+			}
+			if !config.DisableReferrerPolicy {
+				header.Set(enlight.HeaderReferrerPolicy, config.ReferrerPolicy)
+			}
+			return next(c)
+		}
+	}
+}
+
+// isHTTPS reports if the request was sent over HTTPS, either directly or
+// to a trusted proxy.
+func isHTTPS(c enlight.Context) bool {
+	if c.Request().IsTLS() {
+		return true
+	}
+	return c.Enlight().IsTrustedProxy(c.Request().RemoteIP()) &&
+		c.Peek(enlight.HeaderXForwardedProto) == "https"
+}
+
+// CSPNonce returns a random base64 encoded nonce for a
+// Content-Security-Policy.
+func CSPNonce() string {
+	b := make([]byte, 16)
+	randomBytes(b)
+	return base64.StdEncoding.EncodeToString(b)
+}
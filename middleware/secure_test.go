@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"net"
+	"testing"
+
+	"github.com/juliankoehn/enlight"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecure(t *testing.T) {
+	e := enlight.New()
+	e.Use(Secure())
+	e.GET("/", func(c enlight.Context) error {
+		return c.String(200, "ok")
+	})
+
+	ctx := request(e, "GET", "/")
+	assert.Equal(t, "1; mode=block", string(ctx.Response.Header.Peek(enlight.HeaderXXSSProtection)))
+	assert.Equal(t, "nosniff", string(ctx.Response.Header.Peek(enlight.HeaderXContentTypeOptions)))
+	assert.Equal(t, "SAMEORIGIN", string(ctx.Response.Header.Peek(enlight.HeaderXFrameOptions)))
+	assert.Equal(t, "strict-origin-when-cross-origin", string(ctx.Response.Header.Peek(enlight.HeaderReferrerPolicy)))
+	assert.Empty(t, ctx.Response.Header.Peek(enlight.HeaderContentSecurityPolicy))
+	// HSTS is only sent over HTTPS
+	assert.Empty(t, ctx.Response.Header.Peek(enlight.HeaderStrictTransportSecurity))
+
+	// X-Forwarded-Proto is only believed from a trusted proxy
+	ctx = request(e, "GET", "/", enlight.HeaderXForwardedProto, "https")
+	assert.Empty(t, ctx.Response.Header.Peek(enlight.HeaderStrictTransportSecurity))
+	_, proxies, _ := net.ParseCIDR("10.0.0.0/8")
+	e.TrustedProxies = []*net.IPNet{proxies}
+	ctx = request(e, "GET", "/", enlight.HeaderXForwardedProto, "https")
+	assert.Equal(t, "max-age=31536000; includeSubdomains", string(ctx.Response.Header.Peek(enlight.HeaderStrictTransportSecurity)))
+}
+
+func TestSecureWithConfig(t *testing.T) {
+	config := DefaultSecureConfig
+	config.XFrameOptions = "DENY"
+	config.HSTSMaxAge = 600
+	config.HSTSExcludeSubdomains = true
+	config.HSTSPreloadEnabled = true
+	config.ContentSecurityPolicy = "default-src 'self'; script-src 'nonce-${nonce}'"
+	config.CSPReportOnly = true
+
+	e := enlight.New()
+	_, proxies, _ := net.ParseCIDR("10.0.0.0/8")
+	e.TrustedProxies = []*net.IPNet{proxies}
+	e.Use(SecureWithConfig(config))
+	e.GET("/", func(c enlight.Context) error {
+		return c.String(200, c.GetString("csp_nonce"))
+	})
+
+	ctx := request(e, "GET", "/", enlight.HeaderXForwardedProto, "https")
+	nonce := string(ctx.Response.Body())
+	assert.Regexp(t, `^[A-Za-z0-9+/]{22}==$`, nonce)
+	assert.Equal(t, "DENY", string(ctx.Response.Header.Peek(enlight.HeaderXFrameOptions)))
+	assert.Equal(t, "max-age=600; preload", string(ctx.Response.Header.Peek(enlight.HeaderStrictTransportSecurity)))
+	assert.Empty(t, ctx.Response.Header.Peek(enlight.HeaderContentSecurityPolicy))
+	assert.Equal(t, "default-src 'self'; script-src 'nonce-"+nonce+"'",
+		string(ctx.Response.Header.Peek(enlight.HeaderContentSecurityPolicyReportOnly)))
+
+	// Every request gets a new nonce
+	ctx = request(e, "GET", "/")
+	assert.NotEqual(t, nonce, string(ctx.Response.Body()))
+
+	// Empty values get the default, headers are only omitted explicitly
+	e = enlight.New()
+	e.Use(SecureWithConfig(SecureConfig{
+		ContentSecurityPolicy: "default-src 'self'",
+		DisableXFrameOptions:  true,
+	}))
+	e.GET("/", func(c enlight.Context) error {
+		return c.String(200, "ok")
+	})
+	ctx = request(e, "GET", "/")
+	assert.Equal(t, "default-src 'self'", string(ctx.Response.Header.Peek(enlight.HeaderContentSecurityPolicy)))
+	assert.Equal(t, "1; mode=block", string(ctx.Response.Header.Peek(enlight.HeaderXXSSProtection)))
+	assert.Equal(t, "nosniff", string(ctx.Response.Header.Peek(enlight.HeaderXContentTypeOptions)))
+	assert.Equal(t, "strict-origin-when-cross-origin", string(ctx.Response.Header.Peek(enlight.HeaderReferrerPolicy)))
+	assert.Empty(t, ctx.Response.Header.Peek(enlight.HeaderXFrameOptions))
+}
@@ -0,0 +1,108 @@
+package middleware
+
+import (
+	"context"
+	"time"
+
+	"github.com/juliankoehn/enlight"
+	"github.com/juliankoehn/enlight/internal/contexts"
+)
+
+type (
+	// TimeoutConfig defines the config for Timeout middleware.
+	TimeoutConfig struct {
+		// Skipper defines a function to skip middleware
+		Skipper Skipper
+
+		// Timeout is the time the handler has to respond.
+		// Optional. Default value is the timeout of the route set with
+		// `Route#Timeout()`. Route timeouts are set after the middleware
+		// added with `Enlight#Use()` ran, they are only enforced if the
+		// middleware is added to the route or group. Requests without a
+		// timeout are not limited.
+		Timeout time.Duration `yaml:"timeout"`
+
+		// Error is returned to the HTTPErrorHandler when the timeout passed.
+		// Optional. Default value enlight.ErrServiceUnavailable.
+		Error *enlight.HTTPError
+	}
+)
+
+var (
+	// DefaultTimeoutConfig is the default Timeout middleware config.
+	DefaultTimeoutConfig = TimeoutConfig{
+		Skipper: DefaultSkipper,
+		Error:   enlight.ErrServiceUnavailable,
+	}
+)
+
+// Timeout returns a middleware which responds with 503 Service Unavailable
+// if the handler did not return within the timeout of the route. It has to
+// be added to the route, added with `Enlight#Use()` it does not see the
+// route timeout and does nothing.
+//
+// The handler runs in a goroutine on a detached copy of the context, whose
+// response, route path and stored values are copied back if it returns in
+// time. A handler running late
+// only writes to its copy, it should stop once `Context#Ctx()` is done.
+// Streamed responses are not supported.
+func Timeout() enlight.MiddlewareFunc {
+	return TimeoutWithConfig(DefaultTimeoutConfig)
+}
+
+// TimeoutWithConfig returns a Timeout middleware with config.
+// See: `Timeout()`.
+func TimeoutWithConfig(config TimeoutConfig) enlight.MiddlewareFunc {
+	// Defaults
+	if config.Skipper == nil {
+		config.Skipper = DefaultTimeoutConfig.Skipper
+	}
+	if config.Error == nil {
+		config.Error = DefaultTimeoutConfig.Error
+	}
+
+	return func(next enlight.HandleFunc) enlight.HandleFunc {
+		return func(c enlight.Context) error {
+			if config.Skipper(c) {
+				return next(c)
+			}
+
+			var ctx context.Context
+			var cancel context.CancelFunc
+			if config.Timeout > 0 {
+				ctx, cancel = context.WithTimeout(c.Ctx(), config.Timeout)
+			} else if _, ok := c.Ctx().Deadline(); ok {
+				ctx, cancel = context.WithCancel(c.Ctx())
+			} else {
+				return next(c)
+			}
+			// Cancels the late handler once the response has been sent
+			defer cancel()
+
+			d := contexts.Detach(c).(enlight.Context)
+			d.SetCtx(ctx)
+
+			done := make(chan error, 1)
+			panicked := make(chan interface{}, 1)
+			go func() {
+				defer func() {
+					if r := recover(); r != nil {
+						panicked <- r
+					}
+				}()
+				done <- next(d)
+			}()
+
+			select {
+			case err := <-done:
+				contexts.Merge(c, d)
+				return err
+			case r := <-panicked:
+				// Panic in the request goroutine, to be handled by Recover
+				panic(r)
+			case <-ctx.Done():
+				return config.Error
+			}
+		}
+	}
+}
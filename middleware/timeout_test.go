@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/juliankoehn/enlight"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeoutMerge(t *testing.T) {
+	e := enlight.New()
+	var path, user string
+	e.Use(func(next enlight.HandleFunc) enlight.HandleFunc {
+		return func(c enlight.Context) error {
+			err := next(c)
+			path, user = c.Path(), c.GetString("user")
+			return err
+		}
+	}, TimeoutWithConfig(TimeoutConfig{Timeout: time.Second}))
+	e.GET("/users/:id", func(c enlight.Context) error {
+		c.Set("user", "jon")
+		return c.String(200, "ok")
+	})
+
+	ctx := request(e, "GET", "/users/1")
+	assert.Equal(t, "ok", string(ctx.Response.Body()))
+	assert.Equal(t, "/users/:id", path)
+	assert.Equal(t, "jon", user)
+}
+
+func TestTimeout(t *testing.T) {
+	e := enlight.New()
+	e.Logger = enlight.NewLogger(new(bytes.Buffer))
+	e.Use(RequestID())
+	late := make(chan error, 1)
+	e.GET("/fast", func(c enlight.Context) error {
+		return c.String(200, "fast "+c.RequestID())
+	}, TimeoutWithConfig(TimeoutConfig{Timeout: time.Second}))
+	e.GET("/slow", func(c enlight.Context) error {
+		<-c.Ctx().Done()
+		// Writing after the timeout must not reach the response
+		late <- c.String(200, "late")
+		return nil
+	}, Timeout()).Timeout(50 * time.Millisecond)
+	e.GET("/fail", func(c enlight.Context) error {
+		return enlight.NewHTTPError(400, "invalid")
+	}, TimeoutWithConfig(TimeoutConfig{Timeout: time.Second}))
+	e.GET("/panic", func(c enlight.Context) error {
+		panic("boom")
+	}, Recover(), TimeoutWithConfig(TimeoutConfig{Timeout: time.Second}))
+
+	ctx := request(e, "GET", "/fast", enlight.HeaderXRequestID, "req-1")
+	assert.Equal(t, 200, ctx.Response.StatusCode())
+	assert.Equal(t, "fast req-1", string(ctx.Response.Body()))
+	assert.Equal(t, "req-1", string(ctx.Response.Header.Peek(enlight.HeaderXRequestID)))
+
+	ctx = request(e, "GET", "/slow")
+	assert.Equal(t, 503, ctx.Response.StatusCode())
+	assert.NoError(t, <-late)
+	assert.Equal(t, 503, ctx.Response.StatusCode())
+	assert.NotContains(t, string(ctx.Response.Body()), "late")
+
+	ctx = request(e, "GET", "/fail")
+	assert.Equal(t, 400, ctx.Response.StatusCode())
+
+	ctx = request(e, "GET", "/panic")
+	assert.Equal(t, 500, ctx.Response.StatusCode())
+}
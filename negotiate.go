@@ -0,0 +1,94 @@
+package enlight
+
+import (
+	"strconv"
+	"strings"
+)
+
+// acceptRange is a single media range of an Accept header.
+type acceptRange struct {
+	typ, subtype string
+	q            float64
+}
+
+// parseAccept parses the media ranges of an Accept header. Media ranges
+// with an invalid quality value are skipped.
+func parseAccept(header string) []acceptRange {
+	ranges := make([]acceptRange, 0, 4)
+	for _, part := range strings.Split(header, ",") {
+		params := strings.Split(part, ";")
+		mime := strings.ToLower(strings.TrimSpace(params[0]))
+		if mime == "" {
+			continue
+		}
+
+		r := acceptRange{q: 1}
+		if i := strings.IndexByte(mime, '/'); i >= 0 {
+			r.typ, r.subtype = mime[:i], mime[i+1:]
+		} else {
+			r.typ, r.subtype = mime, "*"
+		}
+
+		valid := true
+		for _, param := range params[1:] {
+			param = strings.TrimSpace(param)
+			if !strings.HasPrefix(param, "q=") {
+				continue
+			}
+			q, err := strconv.ParseFloat(param[2:], 64)
+			if err != nil || q < 0 || q > 1 {
+				valid = false
+				break
+			}
+			r.q = q
+		}
+		if valid {
+			ranges = append(ranges, r)
+		}
+	}
+	return ranges
+}
+
+// NegotiateContentType returns the offer preferred by the given Accept
+// header. The quality of an offer is taken from the most specific media
+// range matching it, ties are resolved by the order of offers. An empty
+// header accepts the first offer. If none of the offers is acceptable, an
+// empty string is returned.
+func NegotiateContentType(accept string, offers []string) string {
+	if len(offers) == 0 {
+		return ""
+	}
+	if strings.TrimSpace(accept) == "" {
+		return offers[0]
+	}
+
+	ranges := parseAccept(accept)
+	best, bestQ := "", 0.0
+	for _, offer := range offers {
+		typ, subtype := offer, ""
+		if i := strings.IndexByte(offer, '/'); i >= 0 {
+			typ, subtype = offer[:i], offer[i+1:]
+		}
+
+		q, specificity := 0.0, -1
+		for _, r := range ranges {
+			s := -1
+			switch {
+			case r.typ == typ && r.subtype == subtype:
+				s = 2
+			case r.typ == typ && r.subtype == "*":
+				s = 1
+			case r.typ == "*" && r.subtype == "*":
+				s = 0
+			}
+			if s > specificity {
+				q, specificity = r.q, s
+			}
+		}
+
+		if q > bestQ {
+			best, bestQ = offer, q
+		}
+	}
+	return best
+}
@@ -1,9 +1,15 @@
 package enlight
 
 import (
+	"fmt"
 	"net/http"
+	"net/url"
+	"sort"
 	"strings"
 	"sync"
+	"time"
+
+	"github.com/valyala/fasthttp"
 )
 
 // Router is a http.Handler which can be used to dispatch requests to different
@@ -13,8 +19,26 @@ type Router struct {
 	paramsPool sync.Pool
 	maxParams  uint16
 
+	// Enables automatic redirection if the current route can't be matched but a
+	// handler for the path with (without) the trailing slash exists.
+	// For example if /foo/ is requested but a route only exists for /foo, the
+	// client is redirected to /foo with http status code 301 for GET requests
+	// and 307 for all other request methods.
 	RedirectTrailingSlash bool
 
+	// If enabled, the router tries to fix the current request path, if no
+	// handle is registered for it.
+	// First superfluous path elements like ../ or // are removed.
+	// Afterwards the router does a case-insensitive lookup of the cleaned path.
+	// If a handle can be found for this route, the router makes a redirection
+	// to the corrected path with status code 301 for GET requests and 307 for
+	// all other request methods.
+	// For example /FOO and /..//Foo could be redirected to /foo.
+	// RedirectTrailingSlash is independent of this option.
+	// Note that fasthttp already removes ../ and // from the request path,
+	// unless the server has DisablePathNormalizing set.
+	RedirectFixedPath bool
+
 	// If enabled, adds the matched route path onto the http.Request context
 	// before invoking the handler.
 	// The matched route path is only added to handlers of routes that where
@@ -29,19 +53,58 @@ type Router struct {
 	// handler.
 	HandleMethodNotAllowed bool
 
+	// If enabled, the router automatically replies to OPTIONS requests.
+	// Custom OPTIONS handlers take priority over automatic replies.
+	HandleOPTIONS bool
+
+	// An optional HandleFunc that is called on automatic OPTIONS requests.
+	// The handler is only called if HandleOPTIONS is true and no OPTIONS
+	// handler for the specific path was set.
+	// The "Allow" header is set before calling the handler.
+	// If it is not set, the request is answered with 204 No Content.
+	GlobalOPTIONS HandleFunc
+
 	// Cached value of global (*) allowed methods
 	globalAllowed string
 
-	// Configurable http.Handler which is called when no matching route is found
-	// If it is not set, http.NotFound is used.
-	NotFound http.Handler
+	// Configurable HandleFunc which is called when no matching route is found
+	// If it is not set, NotFoundHandler is used.
+	NotFound HandleFunc
 
-	// Configurable http.Handler which is called when a request
-	// cannot be routed and HandleMethodNowAllowed is true.
-	// If it is not set, http.Error with http.StatusMethodNotAllowed is used.
+	// Configurable HandleFunc which is called when a request
+	// cannot be routed and HandleMethodNotAllowed is true.
+	// If it is not set, ErrMethodNotAllowed is returned to the HTTPErrorHandler.
 	// The "Allow" header with allowed request methods is set before the handler
 	// is called.
-	MethodNotAllowed http.Handler
+	MethodNotAllowed HandleFunc
+
+	// Routes registered by method and path
+	routes map[string]*Route
+
+	// Routes registered by name for reverse URL generation
+	names map[string]*Route
+}
+
+// Route describes a registered route. It is returned on registration
+// and can be used to name the route.
+type Route struct {
+	Method     string `json:"method"`
+	Path       string `json:"path"`
+	name       string
+	handler    string
+	middleware int
+	timeout    time.Duration
+	router     *Router
+}
+
+// RouteInfo holds the details of a registered route, as returned by
+// `Router#Routes()`.
+type RouteInfo struct {
+	Method     string `json:"method"`
+	Path       string `json:"path"`
+	Name       string `json:"name,omitempty"`
+	Handler    string `json:"handler"`
+	Middleware int    `json:"middleware"`
 }
 
 // Param is a single URL parameter, consisting of a key and a value.
@@ -66,6 +129,17 @@ func (ps Params) ByName(name string) string {
 	return ""
 }
 
+// lookup returns the value of the first Param which key matches the given
+// name and whether it was found.
+func (ps Params) lookup(name string) (string, bool) {
+	for i := range ps {
+		if ps[i].Key == name {
+			return ps[i].Value, true
+		}
+	}
+	return "", false
+}
+
 // HandleFunc is a function that can be registered to a route to handle HTTP
 // requests. Like http.HandlerFunc.
 type HandleFunc func(Context) error
@@ -80,7 +154,7 @@ func NewRouter() *Router {
 }
 
 // Handle registers a new request handle with the given path and method.
-func (r *Router) Handle(method, path string, handle HandleFunc, once bool) {
+func (r *Router) Handle(method, path string, handle HandleFunc, once bool) *Route {
 
 	if method == "" {
 		panic("method must not be empty")
@@ -126,6 +200,14 @@ func (r *Router) Handle(method, path string, handle HandleFunc, once bool) {
 			return &ps
 		}
 	}
+
+	if r.routes == nil {
+		r.routes = make(map[string]*Route)
+	}
+	route := &Route{Method: method, Path: path, router: r}
+	r.routes[method+path] = route
+
+	return route
 }
 
 func (r *Router) allowed(path, reqMethod string) (allow string) {
@@ -189,6 +271,111 @@ func (r *Router) Drop(method, path string) {
 	if root := r.trees[method]; root != nil {
 		root.dropRoute(path)
 	}
+	if route, ok := r.routes[method+path]; ok {
+		delete(r.routes, method+path)
+		if route.name != "" {
+			delete(r.names, route.name)
+		}
+	}
+}
+
+// Routes returns all routes currently registered in the trees, ordered
+// by path and method.
+func (r *Router) Routes() []RouteInfo {
+	routes := make([]RouteInfo, 0, len(r.routes))
+	for method, root := range r.trees {
+		root.walk("", func(path string, n *node) {
+			info := RouteInfo{Method: method, Path: path}
+			if route, ok := r.routes[method+path]; ok {
+				info.Name = route.name
+				info.Handler = route.handler
+				info.Middleware = route.middleware
+			}
+			routes = append(routes, info)
+		})
+	}
+
+	sort.Slice(routes, func(i, j int) bool {
+		if routes[i].Path == routes[j].Path {
+			return routes[i].Method < routes[j].Method
+		}
+		return routes[i].Path < routes[j].Path
+	})
+	return routes
+}
+
+// Name registers the route under the given name, which can be used to
+// generate its URL with `Router#URL()`.
+func (route *Route) Name(name string) *Route {
+	r := route.router
+	if _, ok := r.names[name]; ok {
+		panic("a route named '" + name + "' is already registered")
+	}
+	if r.names == nil {
+		r.names = make(map[string]*Route)
+	}
+	route.name = name
+	r.names[name] = route
+	return route
+}
+
+// Timeout sets the deadline of the Go context of requests to the route,
+// as returned by `Context#Ctx()`. The handler is not interrupted, it has
+// to pass the context on or check it.
+func (route *Route) Timeout(d time.Duration) *Route {
+	route.timeout = d
+	return route
+}
+
+// URL generates a URL from the route registered under name. Named
+// parameters and catch-all parameters of the route path are replaced
+// by the values in params.
+func (r *Router) URL(name string, params Params) (string, error) {
+	route, ok := r.names[name]
+	if !ok {
+		return "", ErrUnknownRoute
+	}
+
+	path := route.Path
+	var b strings.Builder
+	b.Grow(len(path))
+
+	for i := 0; i < len(path); i++ {
+		c := path[i]
+		if c != ':' && c != '*' {
+			b.WriteByte(c)
+			continue
+		}
+
+		// Find wildcard end (either '/' or path end)
+		end := i + 1
+		for end < len(path) && path[end] != '/' {
+			end++
+		}
+		key := path[i+1 : end]
+		value, ok := params.lookup(key)
+
+		if c == ':' {
+			if !ok || value == "" {
+				return "", fmt.Errorf("missing parameter '%s' for route '%s'", key, name)
+			}
+			b.WriteString(url.PathEscape(value))
+		} else {
+			if !ok {
+				return "", fmt.Errorf("missing parameter '%s' for route '%s'", key, name)
+			}
+			// The catch-all is always preceded by a '/', which is
+			// part of the matched value as well
+			segments := strings.Split(strings.TrimPrefix(value, "/"), "/")
+			for j := range segments {
+				segments[j] = url.PathEscape(segments[j])
+			}
+			b.WriteString(strings.Join(segments, "/"))
+		}
+		i = end - 1
+	}
+
+	return b.String(), nil
 }
 
 // Find lookup a handler registered for method and path.
@@ -198,8 +385,6 @@ func (r *Router) Find(c Context) {
 	method := string(ctx.RequestCtx.Method())
 	path := string(ctx.RequestCtx.Path())
 
-	ctx.path = path
-
 	if root := r.trees[method]; root != nil {
 		if handle, param, tsr := root.getValue(path); handle != nil {
 			if param != nil {
@@ -208,9 +393,6 @@ func (r *Router) Find(c Context) {
 			ctx.handler = handle
 			return
 		} else if method != "CONNECT" && path != "/" {
-			if param != nil {
-				ctx.params = param
-			}
 			code := 301
 			if method != "GET" {
 				// Temporary redirect, request with same method
@@ -219,19 +401,83 @@ func (r *Router) Find(c Context) {
 			}
 
 			if tsr && r.RedirectTrailingSlash {
-				var uri string
-
 				if len(path) > 1 && path[len(path)-1] == '/' {
-					if len(path) > 1 && path[len(path)-1] == '/' {
-						uri = path[:len(path)-1]
-					} else {
-						uri = path + "/"
-					}
+					ctx.handler = r.redirect(code, path[:len(path)-1])
+				} else {
+					ctx.handler = r.redirect(code, path+"/")
 				}
-
-				ctx.Redirect(code, uri)
 				return
 			}
+
+			// Try to fix the request path
+			if r.RedirectFixedPath {
+				fixedPath, found := root.findCaseInsensitivePath(
+					CleanPath(path),
+					r.RedirectTrailingSlash,
+				)
+				if found {
+					ctx.handler = r.redirect(code, fixedPath)
+					return
+				}
+			}
+		}
+	}
+
+	if method == http.MethodOptions && r.HandleOPTIONS {
+		// Handle OPTIONS requests
+		p := path
+		if string(ctx.RequestCtx.URI().PathOriginal()) == "*" {
+			// server-wide
+			p = "*"
+		}
+		if allow := r.allowed(p, method); allow != "" {
+			ctx.handler = r.options(allow)
+			return
+		}
+	} else if r.HandleMethodNotAllowed {
+		if allow := r.allowed(path, method); allow != "" {
+			ctx.handler = r.methodNotAllowed(allow)
+			return
+		}
+	}
+
+	if r.NotFound != nil {
+		ctx.handler = r.NotFound
+	}
+}
+
+// redirect returns a handler which redirects the request to path with
+// the query string of the request preserved.
+func (r *Router) redirect(code int, path string) HandleFunc {
+	return func(c Context) error {
+		uri := (&url.URL{Path: path}).EscapedPath()
+		if q := c.Request().URI().QueryString(); len(q) > 0 {
+			uri += "?" + string(q)
+		}
+		return c.Redirect(code, uri)
+	}
+}
+
+// options returns a handler which sets the "Allow" header and calls the
+// GlobalOPTIONS handler.
+func (r *Router) options(allow string) HandleFunc {
+	return func(c Context) error {
+		c.Response().Header.Set(HeaderAllow, allow)
+		if r.GlobalOPTIONS != nil {
+			return r.GlobalOPTIONS(c)
+		}
+		return c.NoContent(fasthttp.StatusNoContent)
+	}
+}
+
+// methodNotAllowed returns a handler which sets the "Allow" header and
+// calls the MethodNotAllowed handler.
+func (r *Router) methodNotAllowed(allow string) HandleFunc {
+	return func(c Context) error {
+		c.Response().Header.Set(HeaderAllow, allow)
+		if r.MethodNotAllowed != nil {
+			return r.MethodNotAllowed(c)
 		}
+		return ErrMethodNotAllowed
 	}
 }
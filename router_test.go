@@ -0,0 +1,236 @@
+package enlight
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func TestRouterURL(t *testing.T) {
+	e := New()
+	h := func(c Context) error { return nil }
+
+	e.GET("/users/:id", h).Name("user.show")
+	e.GET("/users/:id/posts/:post", h).Name("user.post")
+	e.Static("/assets", "public").Name("assets")
+	e.Group("/api").GET("/status", h).Name("api.status")
+
+	url, err := e.URL("user.show", Params{{Key: "id", Value: "42"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "/users/42", url)
+
+	url, err = e.URL("user.post", Params{{Key: "post", Value: "hello world"}, {Key: "id", Value: "1"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "/users/1/posts/hello%20world", url)
+
+	url, err = e.URL("assets", Params{{Key: "filepath", Value: "/css/app.css"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "/assets/css/app.css", url)
+
+	url, err = e.URL("assets", Params{{Key: "filepath", Value: "js/app.js"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "/assets/js/app.js", url)
+
+	url, err = e.NewContext().URLFor("api.status", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "/api/status", url)
+
+	_, err = e.URL("user.show", nil)
+	assert.Error(t, err)
+
+	_, err = e.URL("missing", nil)
+	assert.Equal(t, ErrUnknownRoute, err)
+
+	assert.Panics(t, func() {
+		e.POST("/users", h).Name("user.show")
+	})
+
+	e.Drop("GET", "/users/:id")
+	_, err = e.URL("user.show", Params{{Key: "id", Value: "42"}})
+	assert.Equal(t, ErrUnknownRoute, err)
+}
+
+func TestRouterRoutes(t *testing.T) {
+	e := New()
+	h := func(c Context) error { return nil }
+	m := func(next HandleFunc) HandleFunc { return next }
+
+	e.GET("/users/:id", h, m).Name("user.show")
+	e.POST("/users", h)
+	e.Group("/admin", m).DELETE("/users/:id", h, m)
+	e.Static("/assets", "public")
+
+	routes := e.Routes()
+	if assert.Len(t, routes, 4) {
+		assert.Equal(t, RouteInfo{Method: "DELETE", Path: "/admin/users/:id", Handler: handlerName(h), Middleware: 2}, routes[0])
+		assert.Equal(t, "GET", routes[1].Method)
+		assert.Equal(t, "/assets/*filepath", routes[1].Path)
+		assert.Equal(t, RouteInfo{Method: "POST", Path: "/users", Handler: handlerName(h)}, routes[2])
+		assert.Equal(t, RouteInfo{Method: "GET", Path: "/users/:id", Name: "user.show", Handler: handlerName(h), Middleware: 1}, routes[3])
+	}
+
+	buf := new(bytes.Buffer)
+	if assert.NoError(t, e.PrintRoutes(buf)) {
+		var printed []RouteInfo
+		assert.NoError(t, json.Unmarshal(buf.Bytes(), &printed))
+		assert.Equal(t, routes, printed)
+	}
+}
+
+func TestRouterMethodNotAllowed(t *testing.T) {
+	e := New()
+	h := func(c Context) error { return c.String(200, "ok") }
+	e.GET("/users/:id", h)
+	e.PUT("/users/:id", h)
+	e.Use(func(next HandleFunc) HandleFunc {
+		return func(c Context) error {
+			c.Response().Header.Set("X-Middleware", "true")
+			return next(c)
+		}
+	})
+
+	r, _ := http.NewRequest("POST", "http://test/users/1", nil)
+	res, err := serve(e.ServeHTTP, r)
+	if assert.NoError(t, err) {
+		body, _ := ioutil.ReadAll(res.Body)
+		assert.Equal(t, http.StatusMethodNotAllowed, res.StatusCode)
+		assert.Equal(t, "GET, OPTIONS, PUT", res.Header.Get(HeaderAllow))
+		assert.Equal(t, "true", res.Header.Get("X-Middleware"))
+		assert.JSONEq(t, `{"message":"Method Not Allowed"}`, string(body))
+	}
+
+	e.Router.MethodNotAllowed = func(c Context) error {
+		return c.String(http.StatusMethodNotAllowed, "not allowed")
+	}
+	e.Router.NotFound = func(c Context) error {
+		return c.String(http.StatusNotFound, "not found")
+	}
+
+	r, _ = http.NewRequest("DELETE", "http://test/users/1", nil)
+	res, err = serve(e.ServeHTTP, r)
+	if assert.NoError(t, err) {
+		body, _ := ioutil.ReadAll(res.Body)
+		assert.Equal(t, http.StatusMethodNotAllowed, res.StatusCode)
+		assert.Equal(t, "GET, OPTIONS, PUT", res.Header.Get(HeaderAllow))
+		assert.Equal(t, "not allowed", string(body))
+	}
+
+	e.Router.HandleMethodNotAllowed = false
+	r, _ = http.NewRequest("DELETE", "http://test/users/1", nil)
+	res, err = serve(e.ServeHTTP, r)
+	if assert.NoError(t, err) {
+		body, _ := ioutil.ReadAll(res.Body)
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+		assert.Equal(t, "not found", string(body))
+	}
+}
+
+func TestRouterHandleOPTIONS(t *testing.T) {
+	e := New()
+	h := func(c Context) error { return c.String(200, "ok") }
+	e.GET("/users/:id", h)
+	e.DELETE("/users/:id", h)
+	e.POST("/posts", h)
+	e.OPTIONS("/posts", func(c Context) error {
+		return c.String(200, "custom")
+	})
+
+	r, _ := http.NewRequest("OPTIONS", "http://test/users/1", nil)
+	res, err := serve(e.ServeHTTP, r)
+	if assert.NoError(t, err) {
+		assert.Equal(t, http.StatusMethodNotAllowed, res.StatusCode)
+	}
+
+	e.Router.HandleOPTIONS = true
+
+	r, _ = http.NewRequest("OPTIONS", "http://test/users/1", nil)
+	res, err = serve(e.ServeHTTP, r)
+	if assert.NoError(t, err) {
+		assert.Equal(t, http.StatusNoContent, res.StatusCode)
+		assert.Equal(t, "DELETE, GET, OPTIONS", res.Header.Get(HeaderAllow))
+	}
+
+	r, _ = http.NewRequest("OPTIONS", "http://test/posts", nil)
+	res, err = serve(e.ServeHTTP, r)
+	if assert.NoError(t, err) {
+		body, _ := ioutil.ReadAll(res.Body)
+		assert.Equal(t, "custom", string(body))
+	}
+
+	e.Router.GlobalOPTIONS = func(c Context) error {
+		c.Response().Header.Set(HeaderAccessControlAllowOrigin, "*")
+		return c.NoContent(http.StatusOK)
+	}
+
+	r, _ = http.NewRequest("OPTIONS", "http://test/users/1", nil)
+	res, err = serve(e.ServeHTTP, r)
+	if assert.NoError(t, err) {
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, "DELETE, GET, OPTIONS", res.Header.Get(HeaderAllow))
+		assert.Equal(t, "*", res.Header.Get(HeaderAccessControlAllowOrigin))
+	}
+}
+
+func TestRouterRedirect(t *testing.T) {
+	e := New()
+	h := func(c Context) error { return c.String(200, "ok") }
+	e.GET("/users", h)
+	e.GET("/posts/", h)
+	e.POST("/posts/:slug/comments", h)
+
+	type test struct {
+		method   string
+		uri      string
+		code     int
+		location string
+	}
+	run := func(tests []test) {
+		for _, tt := range tests {
+			ctx := new(fasthttp.RequestCtx)
+			ctx.Request.Header.SetMethod(tt.method)
+			ctx.Request.SetRequestURI(tt.uri)
+			e.ServeHTTP(ctx)
+			assert.Equal(t, tt.code, ctx.Response.StatusCode(), tt.uri)
+			assert.Equal(t, tt.location, string(ctx.Response.Header.Peek(HeaderLocation)), tt.uri)
+		}
+	}
+
+	run([]test{
+		{"GET", "http://test/users/", 301, "http://test/users"},
+		{"GET", "http://test/posts?page=2", 301, "http://test/posts/?page=2"},
+		{"GET", "http://test/USERS", 404, ""},
+	})
+
+	e.Router.RedirectFixedPath = true
+
+	run([]test{
+		{"GET", "http://test/USERS?a=b", 301, "http://test/users?a=b"},
+		{"GET", "http://test/Posts", 301, "http://test/posts/"},
+		{"POST", "http://test/POSTS/Hello-World/Comments", 307, "http://test/posts/Hello-World/comments"},
+		{"GET", "http://test/unknown", 404, ""},
+	})
+}
+
+func TestCleanPath(t *testing.T) {
+	tests := map[string]string{
+		"":                 "/",
+		"/":                "/",
+		"abc":              "/abc",
+		"/abc/":            "/abc/",
+		"//abc//def":       "/abc/def",
+		"/abc/./def":       "/abc/def",
+		"/abc/../def":      "/def",
+		"/../abc":          "/abc",
+		"/abc/def/../..":   "/",
+		"/abc/def/.":       "/abc/def/",
+		"/abc/def/ghi/../": "/abc/def/",
+	}
+	for path, expected := range tests {
+		assert.Equal(t, expected, CleanPath(path), path)
+	}
+}
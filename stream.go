@@ -0,0 +1,232 @@
+package enlight
+
+import (
+	"bufio"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	json "github.com/json-iterator/go"
+)
+
+type (
+	// Event is a single Server-Sent Event.
+	Event struct {
+		// ID sets the event ID, which is sent back by reconnecting clients
+		// in the Last-Event-ID header. Line breaks are removed.
+		ID string
+
+		// Event is the event type. Clients dispatch events without type as
+		// "message". Line breaks are removed.
+		Event string
+
+		// Data is the payload of the event. Strings and byte slices are sent
+		// as they are, other values are encoded as JSON. Every line is sent
+		// as a data field.
+		Data interface{}
+
+		// Retry hints the client how long to wait before reconnecting.
+		Retry time.Duration
+	}
+
+	// SSEConfig defines the config for Server-Sent Events streams.
+	SSEConfig struct {
+		// KeepAlive is the interval comments are sent in while no events are,
+		// keeping proxies from closing the connection. Zero disables keep-alives.
+		// Optional. Default value 15 seconds.
+		KeepAlive time.Duration `yaml:"keep_alive"`
+
+		// Retry is the reconnection time sent to the client when the stream starts.
+		// Optional. Not sent by default.
+		Retry time.Duration `yaml:"retry"`
+
+		// BufferSize is the number of events Send queues before blocking.
+		// Optional. Default value 16.
+		BufferSize int `yaml:"buffer_size"`
+	}
+
+	// EventStream writes Server-Sent Events to the client. The stream runs
+	// after the handler returned, events are sent from other goroutines
+	// with Send. It ends with Close, when the client disconnects or when the
+	// shutdown of the server begins.
+	EventStream struct {
+		config      SSEConfig
+		lastEventID string
+		events      chan *Event
+		shutdown    <-chan struct{}
+		closing     chan struct{}
+		done        chan struct{}
+		closeOnce   sync.Once
+		doneOnce    sync.Once
+	}
+)
+
+var (
+	// fieldReplacer removes line breaks from single line fields, which
+	// would start another field
+	fieldReplacer = strings.NewReplacer("\r", "", "\n", "")
+
+	// dataReplacer normalizes the line breaks of data
+	dataReplacer = strings.NewReplacer("\r\n", "\n", "\r", "\n")
+
+	// DefaultSSEConfig is the default Server-Sent Events config.
+	DefaultSSEConfig = SSEConfig{
+		KeepAlive:  15 * time.Second,
+		BufferSize: 16,
+	}
+)
+
+// LastEventID returns the ID of the last event the client received before
+// reconnecting, as sent in the Last-Event-ID header.
+func (s *EventStream) LastEventID() string {
+	return s.lastEventID
+}
+
+// Send queues an event to be sent to the client. It returns ErrStreamClosed
+// if the stream has ended and the error of encoding the data as JSON.
+func (s *EventStream) Send(event Event) error {
+	if err := event.encode(); err != nil {
+		return err
+	}
+
+	select {
+	case <-s.done:
+		return ErrStreamClosed
+	default:
+	}
+
+	select {
+	case s.events <- &event:
+		return nil
+	case <-s.done:
+		return ErrStreamClosed
+	}
+}
+
+// Close ends the stream after the queued events are sent.
+func (s *EventStream) Close() {
+	s.closeOnce.Do(func() {
+		close(s.closing)
+	})
+}
+
+// Done returns a channel that is closed when the stream has ended, either
+// by Close, because the client disconnected or the server shuts down.
+func (s *EventStream) Done() <-chan struct{} {
+	return s.done
+}
+
+// end marks the stream as ended, so Send no longer blocks.
+func (s *EventStream) end() {
+	s.doneOnce.Do(func() {
+		close(s.done)
+	})
+}
+
+func (s *EventStream) run(w *bufio.Writer) {
+	defer s.end()
+
+	// Start the stream right away, the response headers are only sent
+	// along with the first chunk of the body.
+	if s.config.Retry > 0 {
+		writeRetry(w, s.config.Retry)
+	} else {
+		w.WriteString(": ok\n")
+	}
+	w.WriteByte('\n')
+	if err := w.Flush(); err != nil {
+		return
+	}
+
+	var keepAlive <-chan time.Time
+	if s.config.KeepAlive > 0 {
+		ticker := time.NewTicker(s.config.KeepAlive)
+		defer ticker.Stop()
+		keepAlive = ticker.C
+	}
+
+	for {
+		select {
+		case event := <-s.events:
+			if err := event.writeTo(w); err != nil {
+				return
+			}
+		case <-keepAlive:
+			w.WriteString(": keep-alive\n\n")
+		case <-s.shutdown:
+			// End the response, so the shutdown does not wait for it
+			return
+		case <-s.closing:
+			// Send the remaining events before ending the stream
+			for {
+				select {
+				case event := <-s.events:
+					if err := event.writeTo(w); err != nil {
+						return
+					}
+				default:
+					return
+				}
+			}
+		}
+
+		// Flushing fails once the client disconnected
+		if err := w.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// encode converts the data to a string, so an encoding error is returned
+// by Send instead of ending the stream.
+func (e *Event) encode() error {
+	switch d := e.Data.(type) {
+	case nil:
+		e.Data = ""
+	case string:
+	case []byte:
+		e.Data = string(d)
+	default:
+		b, err := json.Marshal(d)
+		if err != nil {
+			return err
+		}
+		e.Data = string(b)
+	}
+	return nil
+}
+
+// writeTo writes the event in the text/event-stream format, the data has
+// to be encoded.
+func (e *Event) writeTo(w *bufio.Writer) error {
+	if e.ID != "" {
+		w.WriteString("id: ")
+		w.WriteString(fieldReplacer.Replace(e.ID))
+		w.WriteByte('\n')
+	}
+	if e.Event != "" {
+		w.WriteString("event: ")
+		w.WriteString(fieldReplacer.Replace(e.Event))
+		w.WriteByte('\n')
+	}
+	if e.Retry > 0 {
+		writeRetry(w, e.Retry)
+	}
+
+	data, _ := e.Data.(string)
+	for _, line := range strings.Split(dataReplacer.Replace(data), "\n") {
+		w.WriteString("data: ")
+		w.WriteString(line)
+		w.WriteByte('\n')
+	}
+
+	_, err := w.WriteString("\n")
+	return err
+}
+
+func writeRetry(w *bufio.Writer, retry time.Duration) {
+	w.WriteString("retry: ")
+	w.WriteString(strconv.FormatInt(int64(retry/time.Millisecond), 10))
+	w.WriteByte('\n')
+}
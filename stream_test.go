@@ -0,0 +1,208 @@
+package enlight
+
+import (
+	"bufio"
+	"bytes"
+	gocontext "context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func TestContextStream(t *testing.T) {
+	e := New()
+	e.GET("/export", func(c Context) error {
+		i := 0
+		return c.Stream(http.StatusOK, "text/csv", func(w *bufio.Writer) bool {
+			if i == 3 {
+				return false
+			}
+			i++
+			w.WriteString(strconv.Itoa(i) + ",row\n")
+			return true
+		})
+	})
+
+	r, _ := http.NewRequest("GET", "http://test/export", nil)
+	res, err := serve(e.ServeHTTP, r)
+	if assert.NoError(t, err) {
+		body, _ := ioutil.ReadAll(res.Body)
+		assert.Equal(t, "text/csv", res.Header.Get(HeaderContentType))
+		assert.Equal(t, "1,row\n2,row\n3,row\n", string(body))
+	}
+}
+
+func TestContextSSE(t *testing.T) {
+	e := New()
+	e.GET("/events", func(c Context) error {
+		s := c.SSEWithConfig(SSEConfig{Retry: 3 * time.Second})
+		go func() {
+			defer s.Close()
+			s.Send(Event{ID: s.LastEventID() + "1", Data: "hello\nworld"})
+			s.Send(Event{ID: "2", Event: "user", Data: user{ID: 1, Name: "Jon Snow"}, Retry: time.Second})
+		}()
+		return nil
+	})
+
+	r, _ := http.NewRequest("GET", "http://test/events", nil)
+	r.Header.Set(HeaderLastEventID, "4")
+	res, err := serve(e.ServeHTTP, r)
+	if assert.NoError(t, err) {
+		body, _ := ioutil.ReadAll(res.Body)
+		assert.Equal(t, MIMETextEventStream, res.Header.Get(HeaderContentType))
+		assert.Equal(t, "no-cache", res.Header.Get(HeaderCacheControl))
+		assert.Equal(t, "retry: 3000\n\n"+
+			"id: 41\ndata: hello\ndata: world\n\n"+
+			"id: 2\nevent: user\nretry: 1000\ndata: "+userJSON+"\n\n", string(body))
+	}
+}
+
+func TestEventStreamDisconnect(t *testing.T) {
+	e := New()
+	streams := make(chan *EventStream, 1)
+	e.GET("/events", func(c Context) error {
+		streams <- c.SSE()
+		return nil
+	})
+
+	r, _ := http.NewRequest("GET", "http://test/events", nil)
+	res, err := serve(e.ServeHTTP, r)
+	if !assert.NoError(t, err) {
+		return
+	}
+	s := <-streams
+	assert.NoError(t, s.Send(Event{Data: "first"}))
+	body := bufio.NewReader(res.Body)
+	body.ReadString('\n') // ": ok"
+	body.ReadString('\n')
+	line, _ := body.ReadString('\n')
+	assert.Equal(t, "data: first\n", line)
+	res.Body.Close()
+
+	// The disconnect is noticed on the next write
+	for i := 0; i < 100 && s.Send(Event{Data: "ping"}) == nil; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	select {
+	case <-s.Done():
+	case <-time.After(time.Second):
+		t.Fatal("stream not closed after client disconnected")
+	}
+	assert.Equal(t, ErrStreamClosed, s.Send(Event{Data: "late"}))
+}
+
+func TestEventStreamDropped(t *testing.T) {
+	e := New()
+	e.Logger = NewLogger(ioutil.Discard)
+	streams := make(chan *EventStream, 1)
+	e.GET("/events", func(c Context) error {
+		streams <- c.SSE()
+		return errors.New("subscription failed")
+	})
+
+	ctx := new(fasthttp.RequestCtx)
+	ctx.Request.SetRequestURI("/events")
+	e.ServeHTTP(ctx)
+	assert.Equal(t, 500, ctx.Response.StatusCode())
+
+	// The error response replaced the stream, Send must not block
+	s := <-streams
+	select {
+	case <-s.Done():
+	default:
+		t.Fatal("dropped stream not ended")
+	}
+	for i := 0; i <= DefaultSSEConfig.BufferSize; i++ {
+		assert.Equal(t, ErrStreamClosed, s.Send(Event{Data: "lost"}))
+	}
+}
+
+func TestEventStreamShutdown(t *testing.T) {
+	e := New()
+	e.HideBanner = true
+	streams := make(chan *EventStream, 1)
+	e.GET("/events", func(c Context) error {
+		s := c.SSE()
+		streams <- s
+		return nil
+	})
+	e.GET("/export", func(c Context) error {
+		return c.Stream(http.StatusOK, "text/csv", func(w *bufio.Writer) bool {
+			w.WriteString("row\n")
+			time.Sleep(10 * time.Millisecond)
+			return true
+		})
+	})
+	completed := make(chan struct{})
+	e.OnShutdownComplete(func(gocontext.Context) error {
+		close(completed)
+		return nil
+	})
+
+	address := freeAddress(t)
+	go e.Start(address)
+	time.Sleep(100 * time.Millisecond)
+
+	client := &http.Client{}
+	for _, path := range []string{"/events", "/export"} {
+		res, err := client.Get("http://" + address + path)
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer res.Body.Close()
+		// Wait for the first chunk, the stream is running
+		bufio.NewReader(res.Body).ReadString('\n')
+	}
+	s := <-streams
+
+	// Open streams end once the shutdown begins, instead of holding it
+	// until the timeout
+	ctx, cancel := gocontext.WithTimeout(gocontext.Background(), 2*time.Second)
+	defer cancel()
+	if assert.NoError(t, e.ShutdownWithContext(ctx)) {
+		<-completed
+	}
+	select {
+	case <-s.Done():
+	case <-time.After(time.Second):
+		t.Error("stream not ended on shutdown")
+	}
+	client.CloseIdleConnections()
+}
+
+func TestEventStreamEncodeError(t *testing.T) {
+	e := New()
+	streams := make(chan *EventStream, 1)
+	e.GET("/events", func(c Context) error {
+		streams <- c.SSE()
+		return nil
+	})
+
+	r, _ := http.NewRequest("GET", "http://test/events", nil)
+	res, err := serve(e.ServeHTTP, r)
+	if !assert.NoError(t, err) {
+		return
+	}
+	s := <-streams
+	// An event which cannot be encoded is refused, the stream goes on
+	assert.Error(t, s.Send(Event{Data: make(chan int)}))
+	assert.NoError(t, s.Send(Event{Data: "next"}))
+	s.Close()
+	body, _ := ioutil.ReadAll(res.Body)
+	assert.Equal(t, ": ok\n\ndata: next\n\n", string(body))
+}
+
+func TestEventFieldInjection(t *testing.T) {
+	buf := new(bytes.Buffer)
+	w := bufio.NewWriter(buf)
+	event := Event{ID: "1\nevent: admin", Event: "user\r\ndata: forged", Data: "a\rb\r\nc"}
+	assert.NoError(t, event.writeTo(w))
+	w.Flush()
+	assert.Equal(t, "id: 1event: admin\nevent: userdata: forged\ndata: a\ndata: b\ndata: c\n\n", buf.String())
+}
@@ -0,0 +1,239 @@
+package enlight
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+type (
+	// TemplateConfig defines the config for the TemplateRenderer.
+	TemplateConfig struct {
+		// Root directory the templates are loaded from.
+		// Optional. Default value "templates".
+		Root string `yaml:"root"`
+
+		// Extension of the template files. Files with other extensions are ignored.
+		// Optional. Default value ".html".
+		Extension string `yaml:"extension"`
+
+		// Layout is the name of the template pages are rendered in, e.g.
+		// "layouts/main". The layout includes the page with
+		// `{{ template "content" . }}`.
+		// Optional. Pages are rendered without a layout by default.
+		Layout string `yaml:"layout"`
+
+		// LayoutsDir is the directory below Root holding the layouts.
+		// Optional. Default value "layouts".
+		LayoutsDir string `yaml:"layouts_dir"`
+
+		// PartialsDir is the directory below Root holding the partials,
+		// which can be included in all pages and layouts, e.g.
+		// `{{ template "partials/header" . }}`.
+		// Optional. Default value "partials".
+		PartialsDir string `yaml:"partials_dir"`
+
+		// Funcs is added to the function map of the templates. The names
+		// "url" and "get" are reserved for the functions of the renderer.
+		// Optional.
+		Funcs template.FuncMap
+	}
+
+	// TemplateRenderer implements the Renderer interface using html/template.
+	// Templates are named by their path relative to the root directory
+	// without extension, e.g. "users/show". If `Enlight#Debug` is set, the
+	// templates are reloaded from disk on every render.
+	TemplateRenderer struct {
+		config    TemplateConfig
+		mutex     sync.RWMutex
+		templates map[string]*templatePage
+	}
+
+	// templatePage is a loaded page and a pool of its clones, which are
+	// executed with the functions bound to a request. The clones are
+	// reused, so html/template escapes each of them only once.
+	templatePage struct {
+		template *template.Template
+		clones   sync.Pool
+	}
+)
+
+var (
+	// DefaultTemplateConfig is the default TemplateRenderer config.
+	DefaultTemplateConfig = TemplateConfig{
+		Root:        "templates",
+		Extension:   ".html",
+		LayoutsDir:  "layouts",
+		PartialsDir: "partials",
+	}
+)
+
+// NewTemplateRenderer returns a TemplateRenderer with the templates loaded
+// from DefaultTemplateConfig.Root.
+func NewTemplateRenderer() (*TemplateRenderer, error) {
+	return NewTemplateRendererWithConfig(DefaultTemplateConfig)
+}
+
+// NewTemplateRendererWithConfig returns a TemplateRenderer with config.
+// See: `NewTemplateRenderer()`.
+func NewTemplateRendererWithConfig(config TemplateConfig) (*TemplateRenderer, error) {
+	// Defaults
+	if config.Root == "" {
+		config.Root = DefaultTemplateConfig.Root
+	}
+	if config.Extension == "" {
+		config.Extension = DefaultTemplateConfig.Extension
+	}
+	if config.LayoutsDir == "" {
+		config.LayoutsDir = DefaultTemplateConfig.LayoutsDir
+	}
+	if config.PartialsDir == "" {
+		config.PartialsDir = DefaultTemplateConfig.PartialsDir
+	}
+	for name := range templateFuncs(nil) {
+		if _, ok := config.Funcs[name]; ok {
+			return nil, fmt.Errorf("template func '%s' is reserved", name)
+		}
+	}
+
+	r := &TemplateRenderer{config: config}
+	if err := r.Load(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Load (re)loads all templates from the root directory.
+func (r *TemplateRenderer) Load() error {
+	shared := template.New("").Funcs(templateFuncs(nil)).Funcs(r.config.Funcs)
+	pages := map[string]string{}
+
+	err := filepath.Walk(r.config.Root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || filepath.Ext(path) != r.config.Extension {
+			return nil
+		}
+
+		rel, err := filepath.Rel(r.config.Root, path)
+		if err != nil {
+			return err
+		}
+		name := strings.TrimSuffix(filepath.ToSlash(rel), r.config.Extension)
+
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		if strings.HasPrefix(name, r.config.LayoutsDir+"/") || strings.HasPrefix(name, r.config.PartialsDir+"/") {
+			_, err = shared.New(name).Parse(string(b))
+			return err
+		}
+		pages[name] = string(b)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	templates := make(map[string]*templatePage, len(pages))
+	for name, src := range pages {
+		t, err := shared.Clone()
+		if err != nil {
+			return err
+		}
+		// The page itself is parsed as "content", which gets included by
+		// the layout. Blocks defined by the page override the layout's.
+		if _, err = t.New("content").Parse(src); err != nil {
+			return fmt.Errorf("%s: %v", name, err)
+		}
+		templates[name] = &templatePage{template: t}
+	}
+
+	r.mutex.Lock()
+	r.templates = templates
+	r.mutex.Unlock()
+	return nil
+}
+
+// Render implements the `Renderer#Render` function.
+func (r *TemplateRenderer) Render(w io.Writer, name string, data interface{}, c Context) error {
+	if c != nil && c.Enlight().Debug {
+		if err := r.Load(); err != nil {
+			return err
+		}
+	}
+
+	r.mutex.RLock()
+	p, ok := r.templates[name]
+	r.mutex.RUnlock()
+	if !ok {
+		return fmt.Errorf("template '%s' not found", name)
+	}
+
+	// Bind the request specific functions to a clone of the template,
+	// the loaded templates are never executed themselves.
+	t, err := p.clone()
+	if err != nil {
+		return err
+	}
+	defer p.release(t)
+	t.Funcs(templateFuncs(c))
+
+	if r.config.Layout != "" {
+		return t.ExecuteTemplate(w, r.config.Layout, data)
+	}
+	return t.ExecuteTemplate(w, "content", data)
+}
+
+// clone returns a clone of the page which is not in use.
+func (p *templatePage) clone() (*template.Template, error) {
+	if t, ok := p.clones.Get().(*template.Template); ok {
+		return t, nil
+	}
+	return p.template.Clone()
+}
+
+// release puts a clone back into the pool, without the functions bound to
+// the request.
+func (p *templatePage) release(t *template.Template) {
+	t.Funcs(templateFuncs(nil))
+	p.clones.Put(t)
+}
+
+// templateFuncs returns the functions bound to the current request, which
+// are available in all templates.
+//
+//	url: generates the URL of a named route, e.g. {{ url "user.show" "id" .ID }}
+//	get: returns a value of the context store, e.g. {{ get "user" }}
+func templateFuncs(c Context) template.FuncMap {
+	return template.FuncMap{
+		"url": func(name string, pairs ...interface{}) (string, error) {
+			if c == nil {
+				return "", errors.New("url can only be used when rendering a request")
+			}
+			if len(pairs)%2 != 0 {
+				return "", errors.New("url expects pairs of parameter names and values")
+			}
+			params := make(Params, 0, len(pairs)/2)
+			for i := 0; i < len(pairs); i += 2 {
+				params = append(params, Param{Key: fmt.Sprint(pairs[i]), Value: fmt.Sprint(pairs[i+1])})
+			}
+			return c.URLFor(name, params)
+		},
+		"get": func(key string) (interface{}, error) {
+			if c == nil {
+				return nil, errors.New("get can only be used when rendering a request")
+			}
+			return c.Get(key), nil
+		},
+	}
+}
@@ -0,0 +1,125 @@
+package enlight
+
+import (
+	"html/template"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeTemplates(t *testing.T, dir string, files map[string]string) {
+	for name, src := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestTemplateRenderer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "enlight-templates")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	writeTemplates(t, dir, map[string]string{
+		"layouts/main.html": `<title>{{ block "title" . }}Enlight{{ end }}</title>{{ template "partials/nav" . }}{{ template "content" . }}`,
+		"partials/nav.html": `<a href="{{ url "user.show" "id" .ID }}">{{ .Name }}</a>`,
+		"users/show.html":   `{{ define "title" }}{{ .Name | shout }}{{ end }}<h1>{{ .Name }}</h1>`,
+		"users/index.html":  `<ul></ul>`,
+		"users/tenant.html": `<p>{{ get "tenant" }}</p>`,
+		"users/ignored.txt": `ignored`,
+	})
+
+	r, err := NewTemplateRendererWithConfig(TemplateConfig{
+		Root:   dir,
+		Layout: "layouts/main",
+		Funcs: template.FuncMap{
+			"shout": strings.ToUpper,
+		},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	e := New()
+	e.Renderer = r
+	e.GET("/users/:id", func(c Context) error {
+		return c.Render(http.StatusOK, "users/show", user{ID: 1, Name: "<Jon>"})
+	}).Name("user.show")
+	e.GET("/users", func(c Context) error {
+		return c.Render(http.StatusOK, "users/index", user{ID: 2, Name: "Arya"})
+	})
+	e.GET("/tenant", func(c Context) error {
+		c.Set("tenant", c.QueryParamDefault("name", "north"))
+		return c.Render(http.StatusOK, "users/tenant", user{ID: 3, Name: "Sansa"})
+	})
+	e.GET("/missing", func(c Context) error {
+		return c.Render(http.StatusOK, "users/ignored", nil)
+	})
+
+	req, _ := http.NewRequest("GET", "http://test/users/1", nil)
+	res, err := serve(e.ServeHTTP, req)
+	if assert.NoError(t, err) {
+		body, _ := ioutil.ReadAll(res.Body)
+		assert.Equal(t, MIMETextHTMLCharsetUTF8, res.Header.Get(HeaderContentType))
+		assert.Equal(t, `<title>&lt;JON&gt;</title><a href="/users/1">&lt;Jon&gt;</a><h1>&lt;Jon&gt;</h1>`, string(body))
+	}
+
+	req, _ = http.NewRequest("GET", "http://test/users", nil)
+	res, err = serve(e.ServeHTTP, req)
+	if assert.NoError(t, err) {
+		body, _ := ioutil.ReadAll(res.Body)
+		assert.Equal(t, `<title>Enlight</title><a href="/users/2">Arya</a><ul></ul>`, string(body))
+	}
+
+	req, _ = http.NewRequest("GET", "http://test/tenant", nil)
+	res, err = serve(e.ServeHTTP, req)
+	if assert.NoError(t, err) {
+		body, _ := ioutil.ReadAll(res.Body)
+		assert.Equal(t, `<title>Enlight</title><a href="/users/3">Sansa</a><p>north</p>`, string(body))
+	}
+
+	req, _ = http.NewRequest("GET", "http://test/missing", nil)
+	res, err = serve(e.ServeHTTP, req)
+	if assert.NoError(t, err) {
+		assert.Equal(t, http.StatusInternalServerError, res.StatusCode)
+	}
+
+	// Reused clones of a page are bound to the current request
+	for _, tenant := range []string{"south", "east", "north"} {
+		req, _ = http.NewRequest("GET", "http://test/tenant?name="+tenant, nil)
+		res, err = serve(e.ServeHTTP, req)
+		if assert.NoError(t, err) {
+			body, _ := ioutil.ReadAll(res.Body)
+			assert.Contains(t, string(body), "<p>"+tenant+"</p>")
+		}
+	}
+
+	_, err = NewTemplateRendererWithConfig(TemplateConfig{
+		Root:  dir,
+		Funcs: template.FuncMap{"url": strings.ToUpper},
+	})
+	assert.EqualError(t, err, "template func 'url' is reserved")
+
+	// Templates are reloaded in debug mode
+	e.Debug = true
+	writeTemplates(t, dir, map[string]string{
+		"users/index.html": `<ol></ol>`,
+	})
+	req, _ = http.NewRequest("GET", "http://test/users", nil)
+	res, err = serve(e.ServeHTTP, req)
+	if assert.NoError(t, err) {
+		body, _ := ioutil.ReadAll(res.Body)
+		assert.Equal(t, `<title>Enlight</title><a href="/users/2">Arya</a><ol></ol>`, string(body))
+	}
+}
@@ -0,0 +1,148 @@
+package enlight
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+// selfSignedCert returns a PEM encoded self-signed certificate and key for host.
+func selfSignedCert(t *testing.T, host string) ([]byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: host},
+		DNSNames:     []string{host},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func TestEnlightStartTLS(t *testing.T) {
+	e := New()
+	e.GET("/", func(c Context) error {
+		if !c.Request().IsTLS() {
+			return c.String(http.StatusOK, "plain")
+		}
+		return c.String(http.StatusOK, "tls")
+	})
+	for _, host := range []string{"a.test", "b.test"} {
+		cert, key := selfSignedCert(t, host)
+		assert.NoError(t, e.AddCertificate(cert, key))
+	}
+
+	address := freeAddress(t)
+	tlsDone := make(chan error, 1)
+	go func() {
+		tlsDone <- e.StartTLSWithConfig(address, nil)
+	}()
+	time.Sleep(100 * time.Millisecond)
+
+	// The certificate is selected by SNI
+	for _, host := range []string{"a.test", "b.test"} {
+		conn, err := tls.Dial("tcp", address, &tls.Config{ServerName: host, InsecureSkipVerify: true})
+		if assert.NoError(t, err) {
+			assert.Equal(t, host, conn.ConnectionState().PeerCertificates[0].Subject.CommonName)
+			conn.Close()
+		}
+	}
+
+	client := http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}}
+	res, err := client.Get("https://" + address + "/")
+	if assert.NoError(t, err) {
+		body, _ := ioutil.ReadAll(res.Body)
+		res.Body.Close()
+		assert.Equal(t, "tls", string(body))
+	}
+	client.CloseIdleConnections()
+
+	// The redirect runs next to the HTTP server of the app
+	redirectAddress, httpAddress := freeAddress(t), freeAddress(t)
+	redirectDone := make(chan error, 1)
+	go func() {
+		redirectDone <- e.StartRedirectHTTPS(redirectAddress, "8443")
+	}()
+	go e.Start(httpAddress)
+	time.Sleep(100 * time.Millisecond)
+
+	noFollow := http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	res, err = noFollow.Get("http://" + redirectAddress + "/users")
+	if assert.NoError(t, err) {
+		res.Body.Close()
+		assert.Equal(t, http.StatusMovedPermanently, res.StatusCode)
+		assert.Equal(t, "https://127.0.0.1:8443/users", res.Header.Get(HeaderLocation))
+	}
+	res, err = noFollow.Get("http://" + httpAddress + "/")
+	if assert.NoError(t, err) {
+		body, _ := ioutil.ReadAll(res.Body)
+		res.Body.Close()
+		assert.Equal(t, "plain", string(body))
+	}
+	noFollow.CloseIdleConnections()
+
+	assert.NoError(t, e.Shutdown())
+	for name, done := range map[string]chan error{"https": tlsDone, "redirect": redirectDone} {
+		select {
+		case err := <-done:
+			assert.NoError(t, err, name)
+		case <-time.After(time.Second):
+			t.Errorf("%s server not shut down", name)
+		}
+	}
+}
+
+func TestEnlightStartTLSWithoutCertificate(t *testing.T) {
+	e := New()
+	assert.Equal(t, ErrCertificateMissing, e.StartTLSWithConfig(":0", &tls.Config{}))
+	assert.Equal(t, ErrCertificateMissing, e.StartTLSWithConfig(":0", nil))
+	assert.Error(t, e.StartTLS(":0", "missing.crt", "missing.key"))
+}
+
+func TestRedirectHTTPS(t *testing.T) {
+	tests := []struct {
+		port, host, uri, location string
+	}{
+		{"", "example.com", "/users?page=2", "https://example.com/users?page=2"},
+		{"443", "example.com:80", "/", "https://example.com/"},
+		{"8443", "example.com:8080", "/a", "https://example.com:8443/a"},
+	}
+
+	for _, tt := range tests {
+		ctx := &fasthttp.RequestCtx{}
+		ctx.Request.SetRequestURI(tt.uri)
+		ctx.Request.Header.SetHost(tt.host)
+		redirectHTTPS(tt.port)(ctx)
+
+		assert.Equal(t, http.StatusMovedPermanently, ctx.Response.StatusCode())
+		assert.Equal(t, tt.location, string(ctx.Response.Header.Peek(HeaderLocation)))
+	}
+}
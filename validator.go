@@ -0,0 +1,275 @@
+package enlight
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+type (
+	// Validator is the interface that wraps the Validate function.
+	Validator interface {
+		Validate(i interface{}) error
+	}
+
+	// ValidationErrors maps field names to the validation errors of the field.
+	ValidationErrors map[string][]string
+
+	// ValidationRule checks field against the rule parameter, which is the
+	// part after the "=" in the tag. It returns an error message if the field
+	// is invalid, otherwise an empty string.
+	ValidationRule func(field reflect.Value, param string) string
+
+	// DefaultValidator validates structs using the `validate` struct tag,
+	// e.g. `validate:"required,min=3,email"`. Fields are reported by their
+	// json name if set. Nested structs are validated as well.
+	DefaultValidator struct {
+		rules map[string]ValidationRule
+	}
+)
+
+var (
+	timeType = reflect.TypeOf(time.Time{})
+
+	emailRegex    = regexp.MustCompile(`^[a-zA-Z0-9.!#$%&'*+/=?^_{|}~-]+@[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?(?:\.[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)*$`)
+	numericRegex  = regexp.MustCompile(`^[-+]?[0-9]+(?:\.[0-9]+)?$`)
+	alphaRegex    = regexp.MustCompile(`^[a-zA-Z]+$`)
+	alphanumRegex = regexp.MustCompile(`^[a-zA-Z0-9]+$`)
+)
+
+// NewValidator returns a DefaultValidator with the built-in rules
+// required, min, max, len, email, url, oneof, numeric, alpha and alphanum.
+func NewValidator() *DefaultValidator {
+	return &DefaultValidator{
+		rules: map[string]ValidationRule{
+			"required": validateRequired,
+			"min":      validateMin,
+			"max":      validateMax,
+			"len":      validateLen,
+			"email":    validateRegex(emailRegex, "must be a valid email address"),
+			"url":      validateURL,
+			"oneof":    validateOneOf,
+			"numeric":  validateRegex(numericRegex, "must be numeric"),
+			"alpha":    validateRegex(alphaRegex, "must only contain letters"),
+			"alphanum": validateRegex(alphanumRegex, "must only contain letters and numbers"),
+		},
+	}
+}
+
+// AddRule registers a custom validation rule by name.
+func (v *DefaultValidator) AddRule(name string, rule ValidationRule) {
+	v.rules[name] = rule
+}
+
+// Validate implements the `Validator#Validate` function. It returns
+// ValidationErrors if any of the fields is invalid.
+func (v *DefaultValidator) Validate(i interface{}) error {
+	val := reflect.Indirect(reflect.ValueOf(i))
+	if val.Kind() != reflect.Struct {
+		return errors.New("validation element must be a struct")
+	}
+
+	errs := ValidationErrors{}
+	v.validateStruct(val, "", errs)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func (v *DefaultValidator) validateStruct(val reflect.Value, prefix string, errs ValidationErrors) {
+	typ := val.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		typeField := typ.Field(i)
+		// Skip unexported fields
+		if typeField.PkgPath != "" {
+			continue
+		}
+		tag := typeField.Tag.Get("validate")
+		if tag == "-" {
+			continue
+		}
+
+		name := prefix + fieldName(typeField)
+		field := val.Field(i)
+
+		if tag != "" {
+			rules := strings.Split(tag, ",")
+			// Optional fields are only validated if they are set
+			if field.IsZero() && !containsRule(rules, "required") {
+				continue
+			}
+
+			for _, rule := range rules {
+				param := ""
+				if j := strings.IndexByte(rule, '='); j >= 0 {
+					rule, param = rule[:j], rule[j+1:]
+				}
+				fn, ok := v.rules[rule]
+				if !ok {
+					panic("unknown validation rule '" + rule + "' on field '" + name + "'")
+				}
+				if msg := fn(reflect.Indirect(field), param); msg != "" {
+					errs[name] = append(errs[name], msg)
+					if rule == "required" {
+						break
+					}
+				}
+			}
+		}
+
+		if inner := reflect.Indirect(field); inner.Kind() == reflect.Struct && inner.Type() != timeType {
+			v.validateStruct(inner, name+".", errs)
+		}
+	}
+}
+
+// fieldName returns the json name of the field, or the field name if
+// it has none.
+func fieldName(field reflect.StructField) string {
+	if tag := field.Tag.Get("json"); tag != "" {
+		if name := strings.Split(tag, ",")[0]; name != "" && name != "-" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+func containsRule(rules []string, name string) bool {
+	for _, rule := range rules {
+		if rule == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Error makes it compatible with `error` interface
+func (ve ValidationErrors) Error() string {
+	fields := make([]string, 0, len(ve))
+	for field := range ve {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	msgs := make([]string, len(fields))
+	for i, field := range fields {
+		msgs[i] = field + ": " + strings.Join(ve[field], ", ")
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Rules
+
+func validateRequired(field reflect.Value, _ string) string {
+	if !field.IsValid() || field.IsZero() {
+		return "is required"
+	}
+	return ""
+}
+
+// size returns the length of strings, slices, maps and arrays or the value
+// of numbers as float, used by min, max and len.
+func size(field reflect.Value) (float64, string) {
+	switch field.Kind() {
+	case reflect.String:
+		return float64(utf8.RuneCountInString(field.String())), "characters"
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return float64(field.Len()), "items"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(field.Int()), ""
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(field.Uint()), ""
+	case reflect.Float32, reflect.Float64:
+		return field.Float(), ""
+	}
+	panic("size rules are not supported for kind " + field.Kind().String())
+}
+
+func parseSizeParam(rule, param string) float64 {
+	n, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		panic("invalid parameter '" + param + "' for validation rule '" + rule + "'")
+	}
+	return n
+}
+
+func validateMin(field reflect.Value, param string) string {
+	min := parseSizeParam("min", param)
+	n, unit := size(field)
+	if n >= min {
+		return ""
+	}
+	switch unit {
+	case "characters":
+		return fmt.Sprintf("must be at least %s characters long", param)
+	case "items":
+		return fmt.Sprintf("must contain at least %s items", param)
+	}
+	return fmt.Sprintf("must be at least %s", param)
+}
+
+func validateMax(field reflect.Value, param string) string {
+	max := parseSizeParam("max", param)
+	n, unit := size(field)
+	if n <= max {
+		return ""
+	}
+	switch unit {
+	case "characters":
+		return fmt.Sprintf("must not be longer than %s characters", param)
+	case "items":
+		return fmt.Sprintf("must not contain more than %s items", param)
+	}
+	return fmt.Sprintf("must not be greater than %s", param)
+}
+
+func validateLen(field reflect.Value, param string) string {
+	l := parseSizeParam("len", param)
+	n, unit := size(field)
+	if n == l {
+		return ""
+	}
+	switch unit {
+	case "characters":
+		return fmt.Sprintf("must be exactly %s characters long", param)
+	case "items":
+		return fmt.Sprintf("must contain exactly %s items", param)
+	}
+	return fmt.Sprintf("must be %s", param)
+}
+
+func validateURL(field reflect.Value, _ string) string {
+	u, err := url.ParseRequestURI(fmt.Sprint(field.Interface()))
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return "must be a valid URL"
+	}
+	return ""
+}
+
+func validateOneOf(field reflect.Value, param string) string {
+	value := fmt.Sprint(field.Interface())
+	options := strings.Fields(param)
+	for _, option := range options {
+		if value == option {
+			return ""
+		}
+	}
+	return "must be one of: " + strings.Join(options, ", ")
+}
+
+func validateRegex(re *regexp.Regexp, msg string) ValidationRule {
+	return func(field reflect.Value, _ string) string {
+		if !re.MatchString(fmt.Sprint(field.Interface())) {
+			return msg
+		}
+		return ""
+	}
+}
@@ -0,0 +1,107 @@
+package enlight
+
+import (
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type (
+	address struct {
+		City string `json:"city" validate:"required"`
+	}
+	signup struct {
+		Name     string   `json:"name" validate:"required,min=3"`
+		Email    string   `json:"email" validate:"required,email"`
+		Website  string   `json:"website" validate:"url"`
+		Age      int      `json:"age" validate:"min=18,max=130"`
+		Role     string   `json:"role" validate:"oneof=admin user"`
+		Tags     []string `json:"tags" validate:"max=2"`
+		Code     *string  `validate:"required,len=4,numeric"`
+		Address  address  `json:"address"`
+		internal string   `validate:"required"`
+	}
+)
+
+func TestValidator(t *testing.T) {
+	v := NewValidator()
+	code := "1234"
+
+	valid := signup{
+		Name:    "Jon",
+		Email:   "jon@winterfell.org",
+		Website: "https://winterfell.org",
+		Age:     18,
+		Role:    "admin",
+		Code:    &code,
+		Address: address{City: "Winterfell"},
+	}
+	assert.NoError(t, v.Validate(&valid))
+
+	invalid := signup{
+		Name:    "Jo",
+		Email:   "jon@",
+		Website: "winterfell",
+		Age:     12,
+		Role:    "king",
+		Tags:    []string{"a", "b", "c"},
+	}
+	err := v.Validate(invalid)
+	assert.Equal(t, ValidationErrors{
+		"name":         {"must be at least 3 characters long"},
+		"email":        {"must be a valid email address"},
+		"website":      {"must be a valid URL"},
+		"age":          {"must be at least 18"},
+		"role":         {"must be one of: admin, user"},
+		"tags":         {"must not contain more than 2 items"},
+		"Code":         {"is required"},
+		"address.city": {"is required"},
+	}, err)
+
+	v.AddRule("uppercase", func(field reflect.Value, _ string) string {
+		if field.String() != strings.ToUpper(field.String()) {
+			return "must be uppercase"
+		}
+		return ""
+	})
+	s := struct {
+		Country string `json:"country" validate:"len=2,uppercase"`
+	}{"de"}
+	assert.Equal(t, ValidationErrors{"country": {"must be uppercase"}}, v.Validate(s))
+}
+
+func TestContextValidate(t *testing.T) {
+	e := New()
+	e.POST("/signup", func(c Context) error {
+		u := new(address)
+		if err := c.Bind(u); err != nil {
+			return err
+		}
+		if err := c.Validate(u); err != nil {
+			return err
+		}
+		return c.NoContent(http.StatusCreated)
+	})
+
+	r, _ := http.NewRequest("POST", "http://test/signup", strings.NewReader(`{"city":""}`))
+	r.Header.Set(HeaderContentType, MIMEApplicationJSON)
+	res, err := serve(e.ServeHTTP, r)
+	if assert.NoError(t, err) {
+		body, _ := ioutil.ReadAll(res.Body)
+		assert.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+		assert.JSONEq(t, `{"city":["is required"]}`, string(body))
+	}
+
+	// Errors other than ValidationErrors are not blamed on the client
+	err = e.NewContext().Validate("not a struct")
+	assert.EqualError(t, err, "validation element must be a struct")
+	_, isHTTPError := err.(*HTTPError)
+	assert.False(t, isHTTPError)
+
+	e.Validator = nil
+	assert.Equal(t, ErrValidatorNotRegistered, e.NewContext().Validate(&address{}))
+}
@@ -0,0 +1,203 @@
+package enlight
+
+import (
+	"strings"
+	"time"
+
+	"github.com/fasthttp/websocket"
+	"github.com/valyala/fasthttp"
+)
+
+type (
+	// WebSocketHandler handles an upgraded WebSocket connection. The Context
+	// is a copy of the request context which stays valid while the
+	// connection is open. The connection is closed when the handler returns.
+	WebSocketHandler func(conn *websocket.Conn, c Context)
+
+	// WebSocketConfig defines the config for WebSocket routes.
+	WebSocketConfig struct {
+		// AllowOrigins lists the origins allowed to open a connection, "*"
+		// allows all origins. If neither AllowOrigins nor CheckOrigin is set,
+		// only requests from the same host are allowed.
+		// Optional.
+		AllowOrigins []string `yaml:"allow_origins"`
+
+		// CheckOrigin decides if the origin of the request is allowed,
+		// replacing AllowOrigins.
+		// Optional.
+		CheckOrigin func(c Context) bool
+
+		// Subprotocols lists the supported subprotocols in order of preference.
+		// The first one requested by the client is selected.
+		// Optional.
+		Subprotocols []string `yaml:"subprotocols"`
+
+		// ReadLimit is the maximum size in bytes of a message read from the
+		// client. The connection is closed if a message exceeds the limit.
+		// Optional. Default value 32768.
+		ReadLimit int64 `yaml:"read_limit"`
+
+		// PingInterval is the interval pings are sent to the client in.
+		// Zero disables pings.
+		// Optional. Default value 54 seconds.
+		PingInterval time.Duration `yaml:"ping_interval"`
+
+		// PongWait is how long to wait for the next pong or message before
+		// the connection is considered dead. It only applies if pings are
+		// sent and must be greater than PingInterval.
+		// Optional. Default value 60 seconds.
+		PongWait time.Duration `yaml:"pong_wait"`
+
+		// WriteWait is the time allowed to send a ping.
+		// Optional. Default value 10 seconds.
+		WriteWait time.Duration `yaml:"write_wait"`
+
+		// HandshakeTimeout is the time allowed to complete the handshake.
+		// Optional.
+		HandshakeTimeout time.Duration `yaml:"handshake_timeout"`
+
+		// ReadBufferSize and WriteBufferSize are the I/O buffer sizes in bytes.
+		// Optional. The buffers of the server are used by default.
+		ReadBufferSize  int `yaml:"read_buffer_size"`
+		WriteBufferSize int `yaml:"write_buffer_size"`
+
+		// EnableCompression negotiates per message compression with the client.
+		// Optional. Default value false.
+		EnableCompression bool `yaml:"enable_compression"`
+	}
+)
+
+var (
+	// DefaultWebSocketConfig is the default WebSocket config.
+	DefaultWebSocketConfig = WebSocketConfig{
+		ReadLimit:    32 << 10,
+		PingInterval: 54 * time.Second,
+		PongWait:     60 * time.Second,
+		WriteWait:    10 * time.Second,
+	}
+)
+
+// WS registers a new WebSocket route for a path with matching handler.
+// The upgrade is a GET request, which runs through the middleware of the
+// route before the connection is upgraded.
+func (e *Enlight) WS(path string, handler WebSocketHandler, m ...MiddlewareFunc) *Route {
+	return e.WSWithConfig(path, handler, DefaultWebSocketConfig, m...)
+}
+
+// WSWithConfig registers a new WebSocket route with config.
+// See: `Enlight#WS()`.
+func (e *Enlight) WSWithConfig(path string, handler WebSocketHandler, config WebSocketConfig, m ...MiddlewareFunc) *Route {
+	route := e.GET(path, webSocket(handler, config), m...)
+	route.handler = handlerName(handler)
+	return route
+}
+
+// WS implements `Enlight#WS()` for sub-routes within the Group.
+func (g *Group) WS(path string, handler WebSocketHandler, m ...MiddlewareFunc) *Route {
+	return g.WSWithConfig(path, handler, DefaultWebSocketConfig, m...)
+}
+
+// WSWithConfig implements `Enlight#WSWithConfig()` for sub-routes within the Group.
+func (g *Group) WSWithConfig(path string, handler WebSocketHandler, config WebSocketConfig, m ...MiddlewareFunc) *Route {
+	route := g.GET(path, webSocket(handler, config), m...)
+	route.handler = handlerName(handler)
+	return route
+}
+
+// webSocket returns the HandleFunc upgrading the requests of a WebSocket route.
+func webSocket(handler WebSocketHandler, config WebSocketConfig) HandleFunc {
+	// Defaults
+	if config.ReadLimit == 0 {
+		config.ReadLimit = DefaultWebSocketConfig.ReadLimit
+	}
+	if config.PongWait == 0 {
+		config.PongWait = DefaultWebSocketConfig.PongWait
+	}
+	if config.WriteWait == 0 {
+		config.WriteWait = DefaultWebSocketConfig.WriteWait
+	}
+
+	upgrader := websocket.FastHTTPUpgrader{
+		HandshakeTimeout:  config.HandshakeTimeout,
+		ReadBufferSize:    config.ReadBufferSize,
+		WriteBufferSize:   config.WriteBufferSize,
+		Subprotocols:      config.Subprotocols,
+		EnableCompression: config.EnableCompression,
+		// Failed handshakes are returned as HTTPError, only keep the status
+		Error: func(ctx *fasthttp.RequestCtx, status int, reason error) {
+			ctx.SetStatusCode(status)
+		},
+	}
+
+	return func(c Context) error {
+		ctx := c.(*context)
+
+		u := upgrader
+		switch {
+		case config.CheckOrigin != nil:
+			u.CheckOrigin = func(*fasthttp.RequestCtx) bool {
+				return config.CheckOrigin(c)
+			}
+		case len(config.AllowOrigins) > 0:
+			u.CheckOrigin = func(r *fasthttp.RequestCtx) bool {
+				return allowOrigin(config.AllowOrigins, string(r.Request.Header.Peek(HeaderOrigin)))
+			}
+		}
+
+		// The connection is handled after the request, when the context
+		// has been released already
+		detached := ctx.detach()
+		err := u.Upgrade(ctx.RequestCtx, func(conn *websocket.Conn) {
+			defer conn.Close()
+			defer detached.release()
+
+			conn.SetReadLimit(config.ReadLimit)
+			if config.PingInterval > 0 {
+				conn.SetReadDeadline(time.Now().Add(config.PongWait))
+				conn.SetPongHandler(func(string) error {
+					return conn.SetReadDeadline(time.Now().Add(config.PongWait))
+				})
+
+				done := make(chan struct{})
+				defer close(done)
+				go webSocketPing(conn, config.PingInterval, config.WriteWait, done)
+			}
+
+			handler(conn, detached)
+		})
+		if err != nil {
+			return NewHTTPError(ctx.RequestCtx.Response.StatusCode(), err.Error())
+		}
+		return nil
+	}
+}
+
+// webSocketPing sends pings to the client until done is closed or sending fails.
+func webSocketPing(conn *websocket.Conn, interval, writeWait time.Duration, done chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeWait)); err != nil {
+				return
+			}
+		case <-done:
+			return
+		}
+	}
+}
+
+// allowOrigin reports if origin is in the list of allowed origins.
+func allowOrigin(allowed []string, origin string) bool {
+	if origin == "" {
+		return true
+	}
+	for _, o := range allowed {
+		if o == "*" || strings.EqualFold(o, origin) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,134 @@
+package enlight
+
+import (
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/fasthttp/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+	"github.com/valyala/fasthttp/fasthttputil"
+)
+
+func dialWebSocket(e *Enlight, path string, header http.Header) (*websocket.Conn, *http.Response, error) {
+	ln := fasthttputil.NewInmemoryListener()
+	go fasthttp.Serve(ln, e.ServeHTTP)
+
+	dialer := websocket.Dialer{
+		NetDial: func(network, addr string) (net.Conn, error) {
+			return ln.Dial()
+		},
+		Subprotocols: header["Sec-Websocket-Protocol"],
+	}
+	header.Del("Sec-Websocket-Protocol")
+	return dialer.Dial("ws://test"+path, header)
+}
+
+func TestEnlightWS(t *testing.T) {
+	e := New()
+	auth := func(next HandleFunc) HandleFunc {
+		return func(c Context) error {
+			if c.QueryParam("token") != "secret" {
+				return NewHTTPError(http.StatusUnauthorized)
+			}
+			return next(c)
+		}
+	}
+	e.WS("/echo/:room", func(conn *websocket.Conn, c Context) {
+		for {
+			mt, msg, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			conn.WriteMessage(mt, []byte(c.Param("room")+": "+string(msg)))
+		}
+	}, auth)
+
+	conn, _, err := dialWebSocket(e, "/echo/lobby?token=secret", http.Header{})
+	if assert.NoError(t, err) {
+		defer conn.Close()
+		assert.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte("hello")))
+		_, msg, err := conn.ReadMessage()
+		assert.NoError(t, err)
+		assert.Equal(t, "lobby: hello", string(msg))
+	}
+
+	// Middleware runs before the upgrade
+	_, res, err := dialWebSocket(e, "/echo/lobby", http.Header{})
+	assert.Equal(t, websocket.ErrBadHandshake, err)
+	if assert.NotNil(t, res) {
+		assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+	}
+}
+
+func TestEnlightWSWithConfig(t *testing.T) {
+	e := New()
+	pinged := make(chan struct{}, 1)
+	e.WSWithConfig("/ws", func(conn *websocket.Conn, c Context) {
+		conn.WriteMessage(websocket.TextMessage, []byte(conn.Subprotocol()))
+		conn.ReadMessage()
+	}, WebSocketConfig{
+		AllowOrigins: []string{"https://example.com"},
+		Subprotocols: []string{"v2", "v1"},
+		PingInterval: 50 * time.Millisecond,
+		PongWait:     time.Second,
+	})
+
+	header := http.Header{}
+	header.Set(HeaderOrigin, "https://example.com")
+	header["Sec-Websocket-Protocol"] = []string{"v1", "v2"}
+	conn, _, err := dialWebSocket(e, "/ws", header)
+	if assert.NoError(t, err) {
+		defer conn.Close()
+		conn.SetPingHandler(func(string) error {
+			select {
+			case pinged <- struct{}{}:
+			default:
+			}
+			return nil
+		})
+		_, msg, err := conn.ReadMessage()
+		assert.NoError(t, err)
+		assert.Equal(t, "v2", string(msg))
+
+		// Pings are handled while reading
+		go conn.ReadMessage()
+		select {
+		case <-pinged:
+		case <-time.After(time.Second):
+			t.Error("no ping received")
+		}
+	}
+
+	header.Set(HeaderOrigin, "https://evil.com")
+	_, res, err := dialWebSocket(e, "/ws", header)
+	assert.Equal(t, websocket.ErrBadHandshake, err)
+	if assert.NotNil(t, res) {
+		assert.Equal(t, http.StatusForbidden, res.StatusCode)
+	}
+
+	// Plain requests are rejected
+	req, _ := http.NewRequest("GET", "http://test/ws", nil)
+	res, err = serve(e.ServeHTTP, req)
+	if assert.NoError(t, err) {
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+	}
+}
+
+func TestEnlightWSReadLimit(t *testing.T) {
+	e := New()
+	errs := make(chan error, 1)
+	e.WSWithConfig("/ws", func(conn *websocket.Conn, c Context) {
+		_, _, err := conn.ReadMessage()
+		errs <- err
+	}, WebSocketConfig{ReadLimit: 8})
+
+	conn, _, err := dialWebSocket(e, "/ws", http.Header{})
+	if assert.NoError(t, err) {
+		defer conn.Close()
+		conn.WriteMessage(websocket.TextMessage, []byte("more than eight bytes"))
+		assert.Equal(t, websocket.ErrReadLimit, <-errs)
+	}
+}